@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/sinisterstuf/dynamo/media"
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// AttractTicks is how long the title screen waits before showing a demo
+const AttractTicks int = 10 * 60
+
+// DemoTicks is the longest a demo plays for before going back to the title
+const DemoTicks int = 60 * 60
+
+// StartDemo shows a bot playing a random maze to attract attention
+// The demo runs in the same simulation a real game does, so it's only started
+// when there isn't a game to continue.
+func (g *Game) StartDemo() {
+	seed := time.Now().UnixNano()
+	rng := rand.New(rand.NewSource(seed))
+	g.Title.Stop()
+	g.Sim = sim.New(seed, sim.LevelEasy+rng.Intn(sim.LevelHard), nil, rng.Intn(2) == 0)
+	g.View = nil
+	g.SyncView()
+	g.Bot = sim.NewBot(seed)
+	g.State = StateAttract
+}
+
+// StopDemo goes back to the title screen from a demo
+func (g *Game) StopDemo() {
+	g.Sim = nil
+	g.View = nil
+	g.Bot = nil
+	g.Idle = 0
+	g.State = StateTitle
+	g.Title.Start(g.Clock)
+}
+
+// Runs the demo for one tick, until any key is pressed or the bot's done
+func updateAttract(g *Game) {
+	if len(inpututil.AppendJustPressedKeys(nil)) > 0 ||
+		g.Sim.Done || g.Sim.Ticks >= DemoTicks {
+		g.StopDemo()
+		return
+	}
+	g.Sim.Step(g.Bot.Input(g.Sim))
+	g.SyncView()
+}
+
+// Draws the demo game with a blinking sign saying it's a demo
+func drawAttract(g *Game, screen *ebiten.Image) {
+	drawLevel(g, screen)
+	if g.BlinkOn {
+		media.DrawText(screen, "DEMO", 0, screen.Bounds().Dy()-6, &media.TextOptions{
+			Inverted: true,
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image"
+	"math"
+)
+
+// CameraMode is how the camera follows the player around mazes that don't
+// fit on the screen
+type CameraMode int
+
+// CameraModes are the different ways the camera can move
+const (
+	CameraSmooth CameraMode = iota // Glide along keeping the player centred
+	CameraFlip                     // Flip a whole screen at a time, Nokia style
+)
+
+// Camera is the window onto the part of the maze that is shown on screen
+// Mazes smaller than the screen are centred on it and never scroll.
+type Camera struct {
+	Mode   CameraMode
+	Screen image.Point     // Size of the view in pixels
+	Area   image.Rectangle // Part of the maze being looked at, e.g. one floor
+	pos    [2]float64      // Top-left corner of the view in coordinates of the Area
+}
+
+// NewCamera makes a Camera looking at a target without any scrolling in
+func NewCamera(mode CameraMode, screen image.Point, area image.Rectangle, target image.Point) *Camera {
+	c := &Camera{
+		Mode:   mode,
+		Screen: screen,
+		Area:   area,
+	}
+	target = target.Sub(area.Min)
+	c.pos[0] = c.aim(target.X, screen.X, area.Dx())
+	c.pos[1] = c.aim(target.Y, screen.Y, area.Dy())
+	return c
+}
+
+// Update moves the Camera one tick closer to looking at a target
+func (c *Camera) Update(target image.Point) {
+	target = target.Sub(c.Area.Min)
+	for i, axis := range [][3]int{
+		{target.X, c.Screen.X, c.Area.Dx()},
+		{target.Y, c.Screen.Y, c.Area.Dy()},
+	} {
+		want := c.aim(axis[0], axis[1], axis[2])
+		if c.Mode == CameraSmooth {
+			c.pos[i] += (want - c.pos[i]) / 8
+			if math.Abs(want-c.pos[i]) < 0.5 {
+				c.pos[i] = want
+			}
+		} else {
+			c.pos[i] = want
+		}
+	}
+}
+
+// Offset is how far to move things in maze coordinates to draw them on screen
+func (c *Camera) Offset() image.Point {
+	return image.Pt(
+		-int(math.Round(c.pos[0]))-c.Area.Min.X,
+		-int(math.Round(c.pos[1]))-c.Area.Min.Y,
+	)
+}
+
+// Works out where the view should start on one axis to show a target
+func (c *Camera) aim(target, screen, bounds int) float64 {
+	if bounds <= screen {
+		return -float64((screen - bounds) / 2)
+	}
+	var start int
+	switch c.Mode {
+	case CameraSmooth:
+		start = target - screen/2
+	case CameraFlip:
+		start = target / screen * screen
+	}
+	return float64(max(0, min(start, bounds-screen)))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// DailyMazes is how many mazes the daily challenge has, one at every level
+var DailyMazes int = len(sim.LevelNames)
+
+// DailyDateFormat is how the date of a daily challenge is written
+const DailyDateFormat string = "2006-01-02"
+
+// ShareMarks are the squares for great, good and poor mazes in shared results
+var ShareMarks = [3]string{"🟩", "🟨", "🟥"}
+
+// ScreenMarks are the same marks as ShareMarks drawable with the pixel font
+var ScreenMarks = [3]string{"#", "+", "-"}
+
+// DailySeed is the seed for the daily challenge of a date in UTC
+// Everyone playing on the same day gets the same mazes without going online.
+func DailySeed(date time.Time) int64 {
+	y, m, d := date.UTC().Date()
+	return int64(y*10000 + int(m)*100 + d)
+}
+
+// NewDaily starts today's daily challenge
+// It always uses each level's own maze algorithm so that it's the same for
+// everyone, whatever their settings.
+func (g *Game) NewDaily() {
+	g.Date = time.Now().UTC()
+	g.startRun(sim.New(DailySeed(g.Date), sim.LevelBeginner, nil, false), ModeDaily)
+}
+
+// checks that a saved daily challenge is still the same one everyone gets,
+// with the day's seed, each level's own maze algorithm and still walls
+func checkDaily(save *SaveGame) error {
+	snap := save.Sim
+	if snap.Seed != DailySeed(save.Date) || snap.Generator != "" || snap.Shifting || snap.Endless {
+		return fmt.Errorf("daily challenge for %s has been changed", save.Date.Format(DailyDateFormat))
+	}
+	return nil
+}
+
+// ends the daily challenge once the player's through its last maze
+// It reports whether the challenge is over.
+func (g *Game) finishDaily() bool {
+	if g.Mode != ModeDaily || len(g.Sim.History) < DailyMazes {
+		return false
+	}
+	share := DailyShare(g.Date, g.Sim.History)
+	if path, err := ConfigPath("daily.txt"); err != nil {
+		log.Println("saving daily result:", err)
+	} else if err := WriteFileAtomic(path, []byte(share+"\n")); err != nil {
+		log.Println("saving daily result:", err)
+	}
+	g.Started = false
+	page := textPage("DAILY", dailyPage(g.Date, g.Sim.History))
+	g.SubmitScore(DailyTable, sim.TotalScore(g.Sim.History), func(g *Game) { g.OpenMenu(page) })
+	return true
+}
+
+// DailyShare is a short summary of a daily challenge for sharing with others
+// It has the total time, steps and torch use, then one square per maze
+// showing how close the player got to the shortest way through.
+func DailyShare(date time.Time, stats []sim.Stats) string {
+	total := sim.Total(stats)
+	return fmt.Sprintf("Dynamo daily %s\n⏱️%s 👣%d 🔦%d%%\n%s",
+		date.Format(DailyDateFormat),
+		formatTicks(total.Ticks),
+		total.Steps,
+		total.TorchPercent(),
+		marks(stats, ShareMarks),
+	)
+}
+
+// lays out the results of a daily challenge for the results screen
+func dailyPage(date time.Time, stats []sim.Stats) string {
+	total := sim.Total(stats)
+	return fmt.Sprintf("%s\nTIME  %s\nSTEPS %d\nTORCH %d%%\n%s",
+		date.Format(DailyDateFormat),
+		formatTicks(total.Ticks),
+		total.Steps,
+		total.TorchPercent(),
+		marks(stats, ScreenMarks),
+	)
+}
+
+// marks each maze as great, good or poor by how many stars it got
+func marks(stats []sim.Stats, set [3]string) string {
+	var b strings.Builder
+	for _, s := range stats {
+		b.WriteString(set[3-s.Stars()])
+	}
+	return b.String()
+}
+
+// writes a number of ticks as minutes and seconds
+func formatTicks(ticks int) string {
+	seconds := ticks / ebiten.DefaultTPS
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sinisterstuf/dynamo/media"
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// EndlessTable is the name of the high score table for endless mode
+const EndlessTable string = "endless"
+
+// DepthBannerTicks is how long the depth is shown for when a maze starts
+const DepthBannerTicks int = 90
+
+// NewEndless starts a run that gets harder and harder until time runs out
+func (g *Game) NewEndless() {
+	seed := time.Now().UnixNano()
+	if g.FixedSeed {
+		seed = g.Seed
+	}
+	g.startRun(sim.NewEndless(seed, g.Gen, g.Shifting), ModeEndless)
+}
+
+// ends the run once time has run out in a maze
+func (g *Game) timeUp() {
+	g.Started = false
+	depth := g.Sim.Depth + 1
+	score := sim.TotalScore(g.Sim.History)
+	page := textPage("TIME UP", fmt.Sprintf("Out of time at depth %d.\nSCORE %d", depth, score))
+	g.SubmitScore(EndlessTable, score, func(g *Game) { g.OpenMenu(page) })
+}
+
+// Draws how deep into the game the maze is for a moment when it starts
+func drawDepth(g *Game, screen *ebiten.Image) {
+	if g.Sim.Stats.Ticks >= DepthBannerTicks {
+		return
+	}
+	media.DrawText(screen, fmt.Sprintf("DEPTH %d", g.Sim.Depth+1), 0, 0, &media.TextOptions{
+		Align:    media.AlignCenter,
+		Width:    screen.Bounds().Dx(),
+		Inverted: true,
+	})
+}
+
+// Draws the time left to get through the maze as a bar along the top edge
+// It blinks when time is nearly up.
+func drawTimeLimit(g *Game, screen *ebiten.Image) {
+	limit := g.Sim.TimeLimit()
+	if limit == 0 {
+		return
+	}
+	left := limit - g.Sim.Stats.Ticks
+	if left*5 < limit && !g.BlinkOn {
+		return
+	}
+	width := float64(left*screen.Bounds().Dx()) / float64(limit)
+	ebitenutil.DrawLine(screen, 0, 0, width, 0, media.ColorLight)
+}
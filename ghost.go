@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// GhostBlinkTicks is how fast the ghost blinks, faster than the player does
+// so the two can be told apart
+const GhostBlinkTicks uint64 = 4
+
+// LoadGhost reads the best route through a maze from the config directory
+// It's nil if the maze hasn't been finished before.
+func LoadGhost(key string) (*sim.Ghost, error) {
+	path, err := ConfigPath("ghosts", key+".ghost")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sim.ReadGhost(bytes.NewReader(data))
+}
+
+// SaveGhost stores the route through a maze in the config directory
+func SaveGhost(ghost *sim.Ghost) error {
+	path, err := ConfigPath("ghosts", ghost.Key()+".ghost")
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	ghost.WriteTo(&buf)
+	return WriteFileAtomic(path, buf.Bytes())
+}
+
+// loads the ghost to race against in the simulation's current maze
+func (g *Game) loadGhost() {
+	ghost, err := LoadGhost(g.Sim.Trail.Key())
+	if err != nil {
+		log.Println("loading ghost:", err)
+	}
+	g.Ghost = ghost
+}
+
+// keeps the route the player just took through a maze if it's their fastest
+// Replays don't count, they're somebody's run that already happened, and nor
+// do runs seeded from the clock, which nobody is going to get to race again.
+func (g *Game) saveGhost() {
+	trail := g.Sim.Trail
+	raceable := g.FixedSeed || g.Mode == ModeDaily
+	if g.Playback != nil || !raceable || !trail.Faster(g.Ghost) {
+		return
+	}
+	if err := SaveGhost(trail); err != nil {
+		log.Println("saving ghost:", err)
+		return
+	}
+	g.Ghost = trail
+}
+
+// Draws the ghost of the best run through the maze, keeping up with the player
+func drawGhost(g *Game, screen *ebiten.Image) {
+	if g.Ghost == nil || (g.Clock.Ticks/GhostBlinkTicks)%2 == 0 {
+		return
+	}
+	pos, ok := g.Ghost.At(g.Sim.Attempt)
+	if !ok || pos.Eq(g.Sim.Player.Coords) {
+		return
+	}
+	drawMarker(g, screen, pos)
+}
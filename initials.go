@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/sinisterstuf/dynamo/media"
+)
+
+// InitialsLetters are the letters initials can be made of
+const InitialsLetters string = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// InitialsEntry is a screen for typing in initials for a new high score
+// Like on an old phone, W and S scroll through the letters, D and E go on to
+// the next letter and A goes back one. E on the last letter is done.
+type InitialsEntry struct {
+	Score   int
+	Letters [3]int // Index of each letter in InitialsLetters
+	Cursor  int    // Which letter is being changed
+	Done    func(g *Game, initials string)
+}
+
+// NewInitialsEntry starts typing initials, beginning from the last ones used
+func NewInitialsEntry(score int, last string, done func(g *Game, initials string)) *InitialsEntry {
+	e := &InitialsEntry{Score: score, Done: done}
+	for i := range e.Letters {
+		if i < len(last) {
+			e.Letters[i] = max(0, strings.IndexByte(InitialsLetters, last[i]))
+		}
+	}
+	return e
+}
+
+// Initials are the letters typed in so far
+func (e *InitialsEntry) Initials() string {
+	b := make([]byte, len(e.Letters))
+	for i, l := range e.Letters {
+		b[i] = InitialsLetters[l]
+	}
+	return string(b)
+}
+
+// Update handles typing for one tick
+func (e *InitialsEntry) Update(g *Game) {
+	n := len(InitialsLetters)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyW):
+		e.Letters[e.Cursor] = (e.Letters[e.Cursor] + 1) % n
+	case inpututil.IsKeyJustPressed(ebiten.KeyS):
+		e.Letters[e.Cursor] = (e.Letters[e.Cursor] + n - 1) % n
+	case inpututil.IsKeyJustPressed(ebiten.KeyA):
+		e.Cursor = max(0, e.Cursor-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyD):
+		e.Cursor = min(len(e.Letters)-1, e.Cursor+1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyE):
+		if e.Cursor < len(e.Letters)-1 {
+			e.Cursor++
+			return
+		}
+		e.Done(g, e.Initials())
+	}
+}
+
+// Draw draws the initials being typed with the current letter highlighted
+func (e *InitialsEntry) Draw(g *Game, screen *ebiten.Image) {
+	screen.Fill(media.ColorLight)
+	width := screen.Bounds().Dx()
+	media.DrawText(screen, "HIGH SCORE!", 0, 1, &media.TextOptions{
+		Color: media.ColorDark,
+		Align: media.AlignCenter,
+		Width: width,
+	})
+	media.DrawText(screen, strconv.Itoa(e.Score), 0, 8, &media.TextOptions{
+		Color: media.ColorDark,
+		Align: media.AlignCenter,
+		Width: width,
+	})
+
+	// The letters are big and spaced out so each one can be highlighted
+	step := media.Font5x7.Size.X + 4
+	x := (width - step*len(e.Letters)) / 2
+	for i, l := range e.Letters {
+		media.DrawText(screen, string(InitialsLetters[l]), x+i*step+2, 20, &media.TextOptions{
+			Font:     media.Font5x7,
+			Color:    media.ColorDark,
+			Inverted: i == e.Cursor,
+		})
+	}
+	media.DrawText(screen, "E TO ENTER", 0, 36, &media.TextOptions{
+		Color: media.ColorDark,
+		Align: media.AlignCenter,
+		Width: width,
+	})
+}
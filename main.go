@@ -2,27 +2,19 @@ package main
 
 import (
 	"errors"
+	"flag"
+	"fmt"
 	"image"
 	"log"
-	"math/rand"
+	"os"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/sinisterstuf/dynamo/media"
-)
-
-// Levels maps level difficulty indices to maze size scaling factors
-var Levels []int = []int{10, 6, 4, 3, 2}
-
-// Levels represent the difficulty of different game levels
-const (
-	LevelBeginner int = iota
-	LevelEasy
-	LevelMedium
-	LevelHard
-	LevelExtreme
+	"github.com/sinisterstuf/dynamo/sim"
+	"github.com/sinisterstuf/dynamo/timer"
 )
 
 // State is a high-level game state controlling app behaviour
@@ -34,116 +26,275 @@ const (
 	StateTitleTransition
 	StateMenu
 	StateLevel
+	StatePaused
+	StateResults
+	StateInitials
+	StateAttract
 )
 
+// Mode is a way of playing through the game
+type Mode int
+
+// Modes are the different kinds of run there are
+const (
+	ModeNormal Mode = iota
+	ModeDaily
+	ModeEndless
+)
+
+// BlinkTicks is how long blinking things stay on or off, half a second
+const BlinkTicks int = 30
+
 func main() {
+	opts, err := ParseOptions(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dynamo:", err)
+		os.Exit(2)
+	}
+	FastForward = opts.FastForward
+
+	// The palette has to be set before any images are made from it
+	if err := media.SetPalette(opts.Palette); err != nil {
+		log.Fatal(err)
+	}
+
 	gameSize := media.GameSize
-	windowScale := 10
-	ebiten.SetWindowSize(gameSize.X*windowScale, gameSize.Y*windowScale)
+	ebiten.SetWindowSize(gameSize.X*opts.Scale, gameSize.Y*opts.Scale)
 	ebiten.SetWindowTitle("Dynamo")
 	ebiten.SetCursorMode(ebiten.CursorModeHidden)
 	ebiten.SetWindowResizable(true)
-
-	source := rand.NewSource(int64(time.Now().Nanosecond()))
+	ebiten.SetFullscreen(opts.Fullscreen)
+	ebiten.SetWindowClosingHandled(true)
 
 	game := &Game{
-		Size:    gameSize,
-		Player:  NewPlayer(),
-		Maze:    NewMaze(source, LevelBeginner, gameSize),
-		BlinkOn: true,
-		Win:     false,
-		Level:   LevelBeginner,
-		Source:  source,
-		Title:   media.NewTitleFrames(),
-		TT:      media.NewTitleTransitionFrames(),
-	}
-
-	go func() {
-		blinker := time.NewTicker(500 * time.Millisecond)
-		for range blinker.C {
-			game.BlinkOn = !game.BlinkOn
+		Size:      gameSize,
+		BlinkOn:   true,
+		Gen:       opts.Gen,
+		Shifting:  opts.Shifting,
+		Seed:      opts.Seed,
+		FixedSeed: opts.FixedSeed,
+		Clock:     timer.New(),
+		Title:     media.NewTitleFrames(),
+		TT:        media.NewTitleTransitionFrames(),
+	}
+	game.Clock.Every(BlinkTicks, func() { game.BlinkOn = !game.BlinkOn })
+
+	scores, err := LoadHighScores()
+	if err != nil {
+		log.Println("loading high scores:", err)
+	}
+	game.Scores = scores
+	// A broken save can still be picked, to find out it's broken
+	save, err := LoadSave()
+	if err != nil {
+		log.Println("loading save:", err)
+	}
+	game.HasSave = save != nil || err != nil
+
+	switch {
+	case opts.Replay != "":
+		r, err := LoadReplay(opts.Replay)
+		if err != nil {
+			log.Fatal(err)
 		}
-	}()
+		game.PlayReplay(r)
+	case opts.Daily:
+		game.NewDaily()
+	case opts.StartGame:
+		game.NewGame(opts.Level)
+	case opts.SkipTitle:
+		game.OpenMenu(NewMainMenu())
+	default:
+		game.Title.Start(game.Clock)
+	}
 
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
+
+	if opts.Record != "" && game.Recording != nil {
+		if err := SaveReplay(opts.Record, game.Recording); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
 // Game tracks global game states
+// The rules of the game itself are run by the simulation, Game only turns key
+// presses into simulation input and draws the result.
 type Game struct {
-	Size    image.Point
-	Player  *Player
-	Maze    *Maze
-	BlinkOn bool
-	Win     bool
-	Level   int
-	Source  rand.Source
-	State   State
-	Title   *media.Animation
-	TT      *media.Animation
+	Size       image.Point
+	Sim        *sim.Sim  // Simulation of the current run, nil before the first
+	Mode       Mode      // What kind of run is being played
+	Date       time.Time // Day of the daily challenge in daily mode
+	View       *MazeView // Images for drawing the simulation's current maze
+	BlinkOn    bool
+	Gen        sim.Generator // Maze algorithm, or nil to pick one per level
+	Shifting   bool          // Whether new runs have shifting walls
+	Seed       int64         // Seed for new games, if FixedSeed is set
+	FixedSeed  bool          // Whether new games use Seed instead of the clock
+	Camera     *Camera
+	CameraMode CameraMode
+	State      State
+	Menu       *Menu            // Menu currently shown in the menu state
+	Started    bool             // Whether a game has been started that can be continued
+	HasSave    bool             // Whether there's a saved game that can be continued
+	Idle       int              // Ticks since a key was last pressed on the title screen
+	Bot        *sim.Bot         // Plays the demo in attract mode
+	Quit       bool             // Set to end the game after the current tick
+	Clock      *timer.Scheduler // Runs timers for everything except when paused
+	Recording  *sim.Replay      // Inputs of the current run so far
+	Playback   *sim.Playback    // Replay being played instead of reading keys
+	Pressed    sim.Input        // Buttons pressed by menus, for the next tick
+	Held       sim.Input        // Buttons held since before the level was entered, ignored until let go
+	Scores     *HighScores
+	Entry      *InitialsEntry // Initials being typed in for a new high score
+	Initials   string         // Initials last typed in, to start from next time
+	Ghost      *sim.Ghost     // Best route through the current maze, if any
+	Title      *media.Animation
+	TT         *media.Animation
+}
+
+// Keys maps the simulation's buttons to keys on the keyboard
+var Keys map[sim.Button]ebiten.Key = map[sim.Button]ebiten.Key{
+	sim.ButtonUp:     ebiten.KeyW,
+	sim.ButtonDown:   ebiten.KeyS,
+	sim.ButtonLeft:   ebiten.KeyA,
+	sim.ButtonRight:  ebiten.KeyD,
+	sim.ButtonTorch:  ebiten.KeyE,
+	sim.ButtonCrankA: ebiten.KeyR,
+	sim.ButtonCrankB: ebiten.KeyF,
+	sim.ButtonHint:   ebiten.KeyH,
+}
+
+// ReadInput takes a snapshot of which buttons are held on the keyboard
+func ReadInput() sim.Input {
+	var in sim.Input
+	for b, key := range Keys {
+		if ebiten.IsKeyPressed(key) {
+			in = in.With(b)
+		}
+	}
+	return in
+}
+
+// goes into the level state from somewhere else, like a menu
+// Keys that are already held down, e.g. the one that picked the menu item,
+// don't count until they've been let go and pressed again.
+func (g *Game) enterLevel() {
+	g.Held = ReadInput()
+	g.State = StateLevel
 }
 
 // Update updates a game by one tick.
 func (g *Game) Update() error {
+	if g.State != StatePaused {
+		g.Clock.Update()
+	}
+
 	switch g.State {
 	case StateTitle:
-		g.Title.Update()
 		if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+			g.Title.Stop()
+			g.TT.Start(g.Clock)
 			g.State = StateTitleTransition
 		}
+		// Show a demo after a while of nothing being pressed
+		g.Idle++
+		if len(inpututil.AppendPressedKeys(nil)) > 0 {
+			g.Idle = 0
+		}
+		if g.Idle >= AttractTicks && g.State == StateTitle && !g.Started {
+			g.StartDemo()
+		}
+	case StateAttract:
+		updateAttract(g)
 	case StateTitleTransition:
-		g.TT.Update()
 		if g.TT.Index == 0 {
-			g.State = StateLevel
+			g.TT.Stop()
+			g.OpenMenu(NewMainMenu())
 		}
+	case StateMenu:
+		g.Menu.Update(g)
 	case StateLevel:
-		return updateLevel(g)
+		updateLevel(g)
+	case StatePaused, StateResults:
+		g.Menu.Update(g)
+	case StateInitials:
+		g.Entry.Update(g)
+	}
+	if ebiten.IsWindowBeingClosed() {
+		g.Quit = true
+	}
+	if g.Quit {
+		g.SaveRun()
+		return ebiten.Termination
 	}
 	return nil
 }
 
-func updateLevel(g *Game) error {
-	// Pressing Q quits immediately
-	if ebiten.IsKeyPressed(ebiten.KeyQ) {
-		return errors.New("game quit by player")
+func updateLevel(g *Game) {
+	// Pressing P pauses, pressing Q asks whether to quit
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.Pause()
+		return
 	}
-
-	if g.Player.Coords.Eq(g.Maze.Exit) {
-		g.Win = true
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		g.Pause()
+		g.Menu = NewQuitMenu(func(g *Game) { g.Resume() })
+		g.Menu.Overlay = true
+		return
 	}
 
-	if g.Win {
-		g.Player.Coords.Y++
-		if g.Player.Coords.Y > g.Size.Y {
-			g.NextLevel()
+	// Holding space fast-forwards through a replay
+	steps := 1
+	if g.Playback != nil && ebiten.IsKeyPressed(ebiten.KeySpace) {
+		steps = FastForward
+	}
+	for range steps {
+		in, ok := g.NextInput()
+		if !ok {
+			g.OpenMenu(textPage("REPLAY", "End of the replay."))
+			return
+		}
+		won := g.Sim.Win
+		g.Sim.Step(in)
+		if g.Sim.Win && !won {
+			g.saveGhost()
+		}
+		if g.Sim.Done {
+			g.ShowResults()
+			return
+		}
+		if g.Sim.Over {
+			g.timeUp()
+			return
 		}
-		return nil
 	}
+	g.SyncView()
+}
 
-	// Movement controls
-	if ebiten.IsKeyPressed(ebiten.KeyS) {
-		g.Player.Move(g.Maze, image.Pt(0, 1), ebiten.KeyS)
+// SyncView keeps the maze view and camera up to date with the simulation
+// A new maze gets new images and a camera that starts out looking at the
+// player, and so does going to another floor.  Otherwise the camera follows
+// the player unless they're leaving.
+func (g *Game) SyncView() {
+	maze, player := g.Sim.Maze, g.Sim.Player
+	if g.View == nil || g.View.Maze != maze {
+		g.View = NewMazeView(maze)
+		g.Camera = nil
+		g.loadGhost()
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyW) {
-		g.Player.Move(g.Maze, image.Pt(0, -1), ebiten.KeyW)
+	if floor := maze.FloorRect(maze.FloorOf(player.Coords)); g.Camera == nil || g.Camera.Area != floor {
+		g.Camera = NewCamera(g.CameraMode, g.Size, floor, player.Coords)
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyA) {
-		g.Player.Move(g.Maze, image.Pt(-1, 0), ebiten.KeyA)
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyD) {
-		g.Player.Move(g.Maze, image.Pt(1, 0), ebiten.KeyD)
-	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
-		g.Player.TorchOn = !g.Player.TorchOn
+	g.View.Update()
+	if !g.Sim.Win {
+		g.Camera.Update(player.Coords)
 	}
-
-	if g.Player.Step > 0 {
-		g.Player.Step--
-	}
-
-	return nil
 }
 
 // Draw draws the game screen by one frame
@@ -153,37 +304,136 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		screen.DrawImage(g.Title.CurrentFrame(), &ebiten.DrawImageOptions{})
 	case StateTitleTransition:
 		screen.DrawImage(g.TT.CurrentFrame(), &ebiten.DrawImageOptions{})
+	case StateAttract:
+		drawAttract(g, screen)
+	case StateMenu:
+		g.Menu.Draw(g, screen)
 	case StateLevel:
 		drawLevel(g, screen)
+	case StatePaused:
+		drawLevel(g, screen)
+		g.Menu.Draw(g, screen)
+	case StateResults:
+		g.Menu.Draw(g, screen)
+	case StateInitials:
+		g.Entry.Draw(g, screen)
 	}
 }
 
+// Draws the floor of the maze the player is on and everything on it
 func drawLevel(g *Game, screen *ebiten.Image) {
+	maze, player := g.Sim.Maze, g.Sim.Player
 	screen.Fill(media.ColorDark)
-	if g.Player.TorchOn {
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(
-			float64(g.Maze.Offset.X),
-			float64(g.Maze.Offset.Y),
-		)
-		// torchLight := image.NewPaletted(g.Maze.Image.Bounds(), media.NokiaPalette)
+	offset := g.Camera.Offset()
+	floor := g.Camera.Area
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(offset.X+floor.Min.X), float64(offset.Y+floor.Min.Y))
+	screen.DrawImage(g.View.Image.SubImage(floor).(*ebiten.Image), op)
+	screen.DrawImage(g.View.Shade().SubImage(floor).(*ebiten.Image), op)
+	if player.TorchOn && maze.FloorOf(player.Coords) == maze.Floors-1 {
+		drawExitGuide(g, screen, maze.Exit.Add(offset))
+	}
+	// Minotaurs are only seen in the torch light, as a dark shape on the floor
+	for _, e := range g.Sim.Enemies {
+		if maze.Grid.In(e.Coords) && maze.Lit[maze.Grid.Index(e.Coords)] {
+			enemyPos := e.Coords.Add(offset)
+			screen.Set(enemyPos.X, enemyPos.Y, media.ColorDark)
+		}
+	}
+
+	drawObjects(g, screen)
+	drawHint(g, screen)
+	drawGhost(g, screen)
+
+	playercolor := media.ColorDark
+	if g.BlinkOn || !player.TorchOn {
+		playercolor = media.ColorLight
+	}
+	playerPos := player.Coords.Add(offset)
+	screen.Set(playerPos.X, playerPos.Y, playercolor)
+	drawBattery(g, screen)
+	drawFloor(g, screen)
+	drawTimeLimit(g, screen)
+	drawDepth(g, screen)
+}
+
+// Draws which floor the player is on down the left edge of the screen, when
+// there's more than one: a dot for each floor from the top one down, with a
+// longer one for the floor they're on
+func drawFloor(g *Game, screen *ebiten.Image) {
+	maze := g.Sim.Maze
+	if maze.Floors < 2 {
+		return
+	}
+	current := maze.FloorOf(g.Sim.Player.Coords)
+	screen.SubImage(image.Rect(0, 1, 3, 2*maze.Floors+2)).(*ebiten.Image).Fill(media.ColorDark)
+	for f := range maze.Floors {
+		screen.Set(0, 2+2*f, media.ColorLight)
+		if f == current {
+			screen.Set(1, 2+2*f, media.ColorLight)
+		}
+	}
+}
 
-		screen.DrawImage(g.Maze.Image, op)
+// Draws a guide line from the exit down to the bottom of the screen
+// When the camera has scrolled away from the exit, a blinking marker on the
+// edge of the screen points the way to it instead.
+func drawExitGuide(g *Game, screen *ebiten.Image, exit image.Point) {
+	bounds := screen.Bounds()
+	if exit.X >= bounds.Min.X && exit.X < bounds.Max.X &&
+		exit.Y >= bounds.Min.Y && exit.Y <= bounds.Max.Y {
 		ebitenutil.DrawLine(
 			screen,
-			float64(g.Maze.Exit.X+g.Maze.Offset.X+1),
-			float64(g.Maze.Exit.Y+g.Maze.Offset.Y),
-			float64(g.Maze.Exit.X+g.Maze.Offset.X+1),
-			float64(screen.Bounds().Max.Y),
+			float64(exit.X+1),
+			float64(exit.Y),
+			float64(exit.X+1),
+			float64(bounds.Max.Y),
 			media.ColorLight,
 		)
+		return
 	}
-	playercolor := media.ColorDark
-	if g.BlinkOn || !g.Player.TorchOn {
-		playercolor = media.ColorLight
+	if !g.BlinkOn {
+		return
 	}
-	playerPos := g.Player.Coords.Add(g.Maze.Offset)
-	screen.Set(playerPos.X, playerPos.Y, playercolor)
+	edge := image.Pt(
+		max(bounds.Min.X, min(exit.X, bounds.Max.X-1)),
+		max(bounds.Min.Y, min(exit.Y, bounds.Max.Y-1)),
+	)
+	// Two pixels along the edge, so it doesn't look like the player
+	along := image.Pt(0, 1)
+	if edge.Y == bounds.Min.Y || edge.Y == bounds.Max.Y-1 {
+		along = image.Pt(1, 0)
+	}
+	tail := edge.Sub(along)
+	if !tail.In(bounds) {
+		tail = edge.Add(along)
+	}
+	screen.Set(edge.X, edge.Y, media.ColorLight)
+	screen.Set(tail.X, tail.Y, media.ColorLight)
+}
+
+// Draws the next steps of the way out while a hint is shown, flashing fast
+func drawHint(g *Game, screen *ebiten.Image) {
+	if (g.Clock.Ticks/GhostBlinkTicks)%2 == 0 {
+		return
+	}
+	for _, p := range g.Sim.Hint {
+		drawMarker(g, screen, p)
+	}
+}
+
+// Draws the torch battery level as a gauge up the right edge of the screen
+// The gauge is drawn over the maze when it is big enough to scroll under it.
+// It blinks when the battery is nearly flat.
+func drawBattery(g *Game, screen *ebiten.Image) {
+	b := g.Sim.Player.Battery
+	if b.Charge*5 < b.Capacity && !g.BlinkOn {
+		return
+	}
+	x := float64(screen.Bounds().Max.X - 1)
+	bottom := float64(screen.Bounds().Max.Y)
+	height := float64(b.Charge*screen.Bounds().Dy()) / float64(b.Capacity)
+	ebitenutil.DrawLine(screen, x, bottom, x, bottom-height, media.ColorLight)
 }
 
 // Layout scales the pixels when the windows is resized
@@ -191,14 +441,3 @@ func drawLevel(g *Game, screen *ebiten.Image) {
 func (g *Game) Layout(outsideWidth int, outsideHeight int) (screenWidth int, screenHeight int) {
 	return g.Size.X, g.Size.Y
 }
-
-// NextLevel sets up the next level of the game
-// It handles things like increasing difficulty and resetting the Player state
-func (g *Game) NextLevel() {
-	g.Win = false
-	g.Player = NewPlayer()
-	if g.Level < LevelExtreme {
-		g.Level++
-	}
-	g.Maze = NewMaze(g.Source, g.Level, g.Size)
-}
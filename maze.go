@@ -2,60 +2,145 @@ package main
 
 import (
 	"image"
-	"math/rand"
+	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/sinisterstuf/dynamo/media"
-	"gitlab.com/zaba505/maze"
+	"github.com/sinisterstuf/dynamo/sim"
 )
 
-// Maze contains all information about mazes
-// Not just the generated maze image but also any other meta-data that can be
-// used for interacting with the maze.
-type Maze struct {
-	Image  *ebiten.Image // Maze image in 1-bit for drawing & collision logic
-	Maze   *maze.Maze    // Original maze object for solving
-	Exit   image.Point   // The exit location, for end-game logic
-	Offset image.Point   // Used to centre the maze at draw time
-}
-
-// NewMaze generates a new maze based on difficulty level and random source
-func NewMaze(source rand.Source, level int, gameSize image.Point) *Maze {
-	mymaze := maze.WithKruskal(source).Generate(
-		gameSize.X/Levels[level]-1,
-		gameSize.Y/Levels[level]-1,
-	)
-
-	// Convert to Nokia colours
-	grayMaze := maze.Gray(mymaze)
-	colorMaze := image.NewPaletted(grayMaze.Bounds(), media.NokiaPalette)
-	for k, v := range grayMaze.Pix {
-		if v == 255 {
-			colorMaze.Pix[k] = 1
+// MazeView draws a simulated maze on the screen
+type MazeView struct {
+	Maze     *sim.Maze     // The maze being drawn
+	Image    *ebiten.Image // Maze image in 1-bit for drawing
+	shade    *ebiten.Image // Overlay for hiding the unlit parts of the maze
+	shadePix []byte        // Pixel buffer for the shade overlay
+	walls    []bool        // Whether each shifting wall was shut when last drawn
+}
+
+// NewMazeView makes the images for drawing a maze
+func NewMazeView(maze *sim.Maze) *MazeView {
+	size := maze.Grid.Size
+	v := &MazeView{
+		Maze:     maze,
+		Image:    ebiten.NewImageFromImage(GridImage(maze.Grid)),
+		shade:    ebiten.NewImage(size.X, size.Y),
+		shadePix: make([]byte, 4*size.X*size.Y),
+		walls:    make([]bool, len(maze.Shifters)),
+	}
+	for k, sh := range maze.Shifters {
+		v.walls[k] = maze.Grid.Wall(sh.Coords)
+	}
+	return v
+}
+
+// Update redraws the shifting walls that have opened or shut since last time
+// Only their own pixels change, the rest of the maze image stays as it is.
+func (v *MazeView) Update() {
+	for k, sh := range v.Maze.Shifters {
+		wall := v.Maze.Grid.Wall(sh.Coords)
+		if wall == v.walls[k] {
+			continue
+		}
+		v.walls[k] = wall
+		c := media.ColorLight
+		if wall {
+			c = media.ColorDark
 		}
+		v.Image.Set(sh.Coords.X, sh.Coords.Y, c)
 	}
+}
 
-	// Find an exit at the bottom right
-	var exit image.Point
-	for i := colorMaze.Rect.Max.X; i > 0; i-- {
-		if colorMaze.At(i, colorMaze.Rect.Max.Y-2) == media.ColorLight {
-			exit = image.Pt(i, colorMaze.Rect.Max.Y)
-			colorMaze.Set(exit.X, exit.Y-1, media.ColorLight)
-			break
+// GridImage draws a Grid as a 1-bit image with dark walls and light corridors
+func GridImage(g *sim.Grid) *image.Paletted {
+	img := image.NewPaletted(image.Rectangle{Max: g.Size}, media.NokiaPalette)
+	for k, wall := range g.Walls {
+		if !wall {
+			img.Pix[k] = 1
 		}
 	}
-	mazeImage := ebiten.NewImageFromImage(colorMaze)
+	return img
+}
 
-	// Calculate offset from origin for centring on the screen
-	offset := image.Pt(
-		(gameSize.X-mazeImage.Bounds().Dx())/2,
-		(gameSize.Y-mazeImage.Bounds().Dy())/2,
-	)
+// Shade returns an overlay that hides every cell of the maze that isn't lit
+// Cells that were explored before but aren't lit now are only half hidden in
+// a checkerboard pattern, so the player can still make out where they've been.
+// The overlay is the same size as the maze image and is meant to be drawn
+// right on top of it.
+func (v *MazeView) Shade() *ebiten.Image {
+	m := v.Maze
+	dark := color.RGBAModel.Convert(media.ColorDark).(color.RGBA)
+	for k, l := range m.Lit {
+		x, y := k%m.Grid.Size.X, k/m.Grid.Size.X
+		px := v.shadePix[k*4 : k*4+4]
+		if l || m.Explored[k] && (x+y)%2 == 0 {
+			px[0], px[1], px[2], px[3] = 0, 0, 0, 0
+		} else {
+			px[0], px[1], px[2], px[3] = dark.R, dark.G, dark.B, dark.A
+		}
+	}
+	v.shade.WritePixels(v.shadePix)
+	return v.shade
+}
 
-	return &Maze{
-		Maze:   mymaze,
-		Image:  mazeImage,
-		Exit:   exit,
-		Offset: offset,
+// Light reports whether a cell shows up light on the screen, so that things
+// drawn on top of it can stand out in the other colour
+func (v *MazeView) Light(p image.Point) bool {
+	g := v.Maze.Grid
+	if !g.In(p) || g.Wall(p) {
+		return false
+	}
+	k := g.Index(p)
+	return v.Maze.Lit[k] || v.Maze.Explored[k] && (p.X+p.Y)%2 == 0
+}
+
+// ObjectBlinkTicks is how long each frame of an object's sprite lasts
+const ObjectBlinkTicks uint64 = 8
+
+// ObjectSprites are how the objects in the maze look
+// Every object is a single pixel, so they're told apart by how they blink:
+// each character is one frame, # for shown and . for not.
+var ObjectSprites = map[sim.ObjectKind]string{
+	sim.ObjectKey:    "##..##..",
+	sim.ObjectDoor:   "#######.",
+	sim.ObjectSwitch: "#.......",
+	sim.ObjectGate:   "####....",
+	sim.ObjectDown:   "#.#.....",
+	sim.ObjectUp:     "#.#.#...",
+	sim.ObjectPortal: "#.",
+}
+
+// Draws the objects in the parts of the maze the player has seen
+// Keys that have been picked up and doors and gates that are open are gone,
+// pressed switches stay on the floor but stop blinking.  Stairs are always
+// there.
+func drawObjects(g *Game, screen *ebiten.Image) {
+	maze := g.View.Maze
+	for _, o := range maze.Objects {
+		if !maze.Explored[maze.Grid.Index(o.Coords)] {
+			continue
+		}
+		if o.Done && o.Kind != sim.ObjectSwitch {
+			continue
+		}
+		sprite := ObjectSprites[o.Kind]
+		if o.Done || sprite[(g.Clock.Ticks/ObjectBlinkTicks)%uint64(len(sprite))] == '#' {
+			drawMarker(g, screen, o.Coords)
+		}
+	}
+}
+
+// Draws a one-pixel marker on a maze cell in whichever colour stands out from
+// what's under it, if it's on the floor being shown
+func drawMarker(g *Game, screen *ebiten.Image, p image.Point) {
+	maze := g.View.Maze
+	if maze.FloorOf(p) != maze.FloorOf(g.Sim.Player.Coords) {
+		return
+	}
+	c := media.ColorLight
+	if g.View.Light(p) {
+		c = media.ColorDark
 	}
+	p = p.Add(g.Camera.Offset())
+	screen.Set(p.X, p.Y, c)
 }
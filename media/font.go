@@ -0,0 +1,216 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Font is a fixed-width bitmap font for drawing text on the tiny screen
+type Font struct {
+	Size    image.Point // Size of one glyph in pixels
+	Spacing int         // Gap between glyphs and between lines in pixels
+	glyphs  map[rune][]string
+	images  map[rune]*ebiten.Image
+}
+
+// LineHeight returns how far apart lines of text are in pixels
+func (f *Font) LineHeight() int {
+	return f.Size.Y + f.Spacing
+}
+
+// Width returns how many pixels wide a single line of text is
+func (f *Font) Width(line string) int {
+	n := len([]rune(line))
+	if n == 0 {
+		return 0
+	}
+	return n*(f.Size.X+f.Spacing) - f.Spacing
+}
+
+// Align is how lines of text line up with each other
+type Align int
+
+// Aligns are the ways text can be lined up
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextOptions control how DrawText lays out and colours text
+// Without a Width the text is anchored at its position: by its left edge,
+// centre or right edge depending on the Align.  With a Width the text is word
+// wrapped to fit and aligned inside a block that starts at its position.
+type TextOptions struct {
+	Font     *Font       // Defaults to Font3x5
+	Color    color.Color // Defaults to ColorLight
+	Align    Align
+	Width    int  // Block width to wrap text to, 0 to only break at newlines
+	Inverted bool // Draw the other colour on a box of Color, e.g. highlights
+}
+
+// DrawText draws text onto an image with its top at a point
+// It returns the area that was drawn over, including any inverted box.
+func DrawText(dst *ebiten.Image, text string, x, y int, opts *TextOptions) image.Rectangle {
+	if opts == nil {
+		opts = &TextOptions{}
+	}
+	f := opts.font()
+	fg := opts.Color
+	if fg == nil {
+		fg = ColorLight
+	}
+	if opts.Inverted {
+		fg = Inverse(fg)
+	}
+
+	var drawn image.Rectangle
+	for i, line := range WrapText(text, f, opts.Width) {
+		lineY := y + i*f.LineHeight()
+		w := f.Width(line)
+
+		// Work out where the line and the box behind it go
+		var lineX int
+		var box image.Rectangle
+		if opts.Width > 0 {
+			box = image.Rect(x, lineY-f.Spacing, x+opts.Width, lineY+f.Size.Y)
+			switch opts.Align {
+			case AlignLeft:
+				lineX = x + 1
+			case AlignCenter:
+				lineX = x + (opts.Width-w)/2
+			case AlignRight:
+				lineX = x + opts.Width - w - 1
+			}
+		} else {
+			switch opts.Align {
+			case AlignLeft:
+				lineX = x
+			case AlignCenter:
+				lineX = x - w/2
+			case AlignRight:
+				lineX = x - w
+			}
+			box = image.Rect(lineX-1, lineY-f.Spacing, lineX+w+1, lineY+f.Size.Y)
+		}
+
+		if opts.Inverted {
+			dst.SubImage(box).(*ebiten.Image).Fill(opts.Color)
+			drawn = drawn.Union(box)
+		} else {
+			drawn = drawn.Union(image.Rect(lineX, lineY, lineX+w, lineY+f.Size.Y))
+		}
+		for _, r := range line {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(lineX), float64(lineY))
+			op.ColorScale.ScaleWithColor(fg)
+			dst.DrawImage(f.glyph(r), op)
+			lineX += f.Size.X + f.Spacing
+		}
+	}
+	return drawn
+}
+
+// MeasureText returns the size in pixels of text laid out with options
+func MeasureText(text string, opts *TextOptions) image.Point {
+	if opts == nil {
+		opts = &TextOptions{}
+	}
+	f := opts.font()
+	lines := WrapText(text, f, opts.Width)
+	size := image.Pt(opts.Width, len(lines)*f.LineHeight()-f.Spacing)
+	if opts.Width == 0 {
+		for _, line := range lines {
+			size.X = max(size.X, f.Width(line))
+		}
+	}
+	return size
+}
+
+// WrapText splits text into lines that fit within a width in pixels
+// Lines are broken between words where possible and words that are too long
+// for a line on their own are split up.  Newlines always start a new line.
+// A width of 0 means lines are only broken at newlines.
+func WrapText(text string, f *Font, width int) []string {
+	paragraphs := strings.Split(text, "\n")
+	if width <= 0 {
+		return paragraphs
+	}
+	// Inside a block text is inset by a pixel on each side
+	perLine := max(1, (width-2+f.Spacing)/(f.Size.X+f.Spacing))
+
+	var lines []string
+	for _, p := range paragraphs {
+		if len([]rune(p)) <= perLine { // keep any spacing in lines that fit
+			lines = append(lines, p)
+			continue
+		}
+		var line []rune
+		for _, word := range strings.Fields(p) {
+			w := []rune(word)
+			if len(line) > 0 && len(line)+1+len(w) <= perLine {
+				line = append(append(line, ' '), w...)
+				continue
+			}
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+			}
+			for len(w) > perLine {
+				lines = append(lines, string(w[:perLine]))
+				w = w[perLine:]
+			}
+			line = w
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// Inverse returns the other colour of the 1-bit palette
+func Inverse(c color.Color) color.Color {
+	if NokiaPalette.Index(c) == 0 {
+		return ColorLight
+	}
+	return ColorDark
+}
+
+// returns the font to use, falling back to the default
+func (opts *TextOptions) font() *Font {
+	if opts.Font == nil {
+		return Font3x5
+	}
+	return opts.Font
+}
+
+// returns a white image of a glyph, making it the first time it's needed
+// Characters the font doesn't have are tried as capitals and then drawn as a
+// question mark if that doesn't help either.
+func (f *Font) glyph(r rune) *ebiten.Image {
+	if _, ok := f.glyphs[r]; !ok {
+		r = unicode.ToUpper(r)
+	}
+	if _, ok := f.glyphs[r]; !ok {
+		r = '?'
+	}
+	if img, ok := f.images[r]; ok {
+		return img
+	}
+	if f.images == nil {
+		f.images = make(map[rune]*ebiten.Image)
+	}
+	pix := image.NewAlpha(image.Rectangle{Max: f.Size})
+	for y, row := range f.glyphs[r] {
+		for x, c := range row {
+			if c == '#' {
+				pix.Pix[pix.PixOffset(x, y)] = 0xff
+			}
+		}
+	}
+	img := ebiten.NewImageFromImage(pix)
+	f.images[r] = img
+	return img
+}
@@ -0,0 +1,67 @@
+package media
+
+import "image"
+
+// Font3x5 is a tiny capitals-only font, like the menus of a Nokia 3310
+// Lowercase letters are drawn as capitals.
+var Font3x5 *Font = &Font{
+	Size:    image.Pt(3, 5),
+	Spacing: 1,
+	glyphs: map[rune][]string{
+		' ':  {"...", "...", "...", "...", "..."},
+		'A':  {".#.", "#.#", "###", "#.#", "#.#"},
+		'B':  {"##.", "#.#", "##.", "#.#", "##."},
+		'C':  {".##", "#..", "#..", "#..", ".##"},
+		'D':  {"##.", "#.#", "#.#", "#.#", "##."},
+		'E':  {"###", "#..", "##.", "#..", "###"},
+		'F':  {"###", "#..", "##.", "#..", "#.."},
+		'G':  {".##", "#..", "#.#", "#.#", ".##"},
+		'H':  {"#.#", "#.#", "###", "#.#", "#.#"},
+		'I':  {"###", ".#.", ".#.", ".#.", "###"},
+		'J':  {"..#", "..#", "..#", "#.#", ".#."},
+		'K':  {"#.#", "#.#", "##.", "#.#", "#.#"},
+		'L':  {"#..", "#..", "#..", "#..", "###"},
+		'M':  {"#.#", "###", "###", "#.#", "#.#"},
+		'N':  {"##.", "#.#", "#.#", "#.#", "#.#"},
+		'O':  {".#.", "#.#", "#.#", "#.#", ".#."},
+		'P':  {"##.", "#.#", "##.", "#..", "#.."},
+		'Q':  {".#.", "#.#", "#.#", "##.", ".##"},
+		'R':  {"##.", "#.#", "##.", "#.#", "#.#"},
+		'S':  {".##", "#..", ".#.", "..#", "##."},
+		'T':  {"###", ".#.", ".#.", ".#.", ".#."},
+		'U':  {"#.#", "#.#", "#.#", "#.#", "###"},
+		'V':  {"#.#", "#.#", "#.#", "#.#", ".#."},
+		'W':  {"#.#", "#.#", "###", "###", "#.#"},
+		'X':  {"#.#", "#.#", ".#.", "#.#", "#.#"},
+		'Y':  {"#.#", "#.#", ".#.", ".#.", ".#."},
+		'Z':  {"###", "..#", ".#.", "#..", "###"},
+		'0':  {"###", "#.#", "#.#", "#.#", "###"},
+		'1':  {".#.", "##.", ".#.", ".#.", "###"},
+		'2':  {"##.", "..#", ".#.", "#..", "###"},
+		'3':  {"##.", "..#", ".#.", "..#", "##."},
+		'4':  {"#.#", "#.#", "###", "..#", "..#"},
+		'5':  {"###", "#..", "##.", "..#", "##."},
+		'6':  {".##", "#..", "###", "#.#", "###"},
+		'7':  {"###", "..#", ".#.", ".#.", ".#."},
+		'8':  {"###", "#.#", "###", "#.#", "###"},
+		'9':  {"###", "#.#", "###", "..#", "##."},
+		'.':  {"...", "...", "...", "...", ".#."},
+		',':  {"...", "...", "...", ".#.", "#.."},
+		':':  {"...", ".#.", "...", ".#.", "..."},
+		'!':  {".#.", ".#.", ".#.", "...", ".#."},
+		'?':  {"##.", "..#", ".#.", "...", ".#."},
+		'-':  {"...", "...", "###", "...", "..."},
+		'+':  {"...", ".#.", "###", ".#.", "..."},
+		'/':  {"..#", "..#", ".#.", "#..", "#.."},
+		'\'': {".#.", ".#.", "...", "...", "..."},
+		'(':  {".#.", "#..", "#..", "#..", ".#."},
+		')':  {".#.", "..#", "..#", "..#", ".#."},
+		'<':  {"..#", ".#.", "#..", ".#.", "..#"},
+		'>':  {"#..", ".#.", "..#", ".#.", "#.."},
+		'=':  {"...", "###", "...", "###", "..."},
+		'%':  {"#.#", "..#", ".#.", "#..", "#.#"},
+		'*':  {"#.#", ".#.", "#.#", "...", "..."},
+		'_':  {"...", "...", "...", "...", "###"},
+		'#':  {"#.#", "###", "#.#", "###", "#.#"},
+	},
+}
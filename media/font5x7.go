@@ -0,0 +1,106 @@
+package media
+
+import "image"
+
+// Font5x7 is a larger font with lowercase letters, for longer messages
+var Font5x7 *Font = &Font{
+	Size:    image.Pt(5, 7),
+	Spacing: 1,
+	glyphs: map[rune][]string{
+		' ':  {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+		'A':  {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+		'B':  {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+		'C':  {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+		'D':  {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+		'E':  {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+		'F':  {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+		'G':  {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+		'H':  {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+		'I':  {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+		'J':  {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+		'K':  {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+		'L':  {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+		'M':  {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+		'N':  {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+		'O':  {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+		'P':  {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+		'Q':  {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+		'R':  {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+		'S':  {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+		'T':  {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+		'U':  {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+		'V':  {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+		'W':  {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+		'X':  {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+		'Y':  {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+		'Z':  {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+		'a':  {".....", ".....", ".###.", "....#", ".####", "#...#", ".####"},
+		'b':  {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "####."},
+		'c':  {".....", ".....", ".###.", "#....", "#....", "#...#", ".###."},
+		'd':  {"....#", "....#", ".##.#", "#..##", "#...#", "#...#", ".####"},
+		'e':  {".....", ".....", ".###.", "#...#", "#####", "#....", ".###."},
+		'f':  {"..##.", ".#..#", ".#...", "###..", ".#...", ".#...", ".#..."},
+		'g':  {".....", ".####", "#...#", "#...#", ".####", "....#", ".###."},
+		'h':  {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+		'i':  {"..#..", ".....", ".##..", "..#..", "..#..", "..#..", ".###."},
+		'j':  {"...#.", ".....", "..##.", "...#.", "...#.", "#..#.", ".##.."},
+		'k':  {".#...", ".#...", ".#..#", ".#.#.", ".##..", ".#.#.", ".#..#"},
+		'l':  {".##..", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+		'm':  {".....", ".....", "##.#.", "#.#.#", "#.#.#", "#...#", "#...#"},
+		'n':  {".....", ".....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+		'o':  {".....", ".....", ".###.", "#...#", "#...#", "#...#", ".###."},
+		'p':  {".....", "####.", "#...#", "#...#", "####.", "#....", "#...."},
+		'q':  {".....", ".##.#", "#..##", "#...#", ".####", "....#", "....#"},
+		'r':  {".....", ".....", "#.##.", "##..#", "#....", "#....", "#...."},
+		's':  {".....", ".....", ".###.", "#....", ".###.", "....#", "####."},
+		't':  {".#...", ".#...", "###..", ".#...", ".#...", ".#..#", "..##."},
+		'u':  {".....", ".....", "#...#", "#...#", "#...#", "#..##", ".##.#"},
+		'v':  {".....", ".....", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+		'w':  {".....", ".....", "#...#", "#...#", "#.#.#", "#.#.#", ".#.#."},
+		'x':  {".....", ".....", "#...#", ".#.#.", "..#..", ".#.#.", "#...#"},
+		'y':  {".....", "#...#", "#...#", "#...#", ".####", "....#", ".###."},
+		'z':  {".....", ".....", "#####", "...#.", "..#..", ".#...", "#####"},
+		'0':  {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+		'1':  {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+		'2':  {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+		'3':  {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+		'4':  {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+		'5':  {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+		'6':  {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+		'7':  {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+		'8':  {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+		'9':  {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+		'!':  {"..#..", "..#..", "..#..", "..#..", "..#..", ".....", "..#.."},
+		'"':  {".#.#.", ".#.#.", ".....", ".....", ".....", ".....", "....."},
+		'#':  {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+		'$':  {"..#..", ".####", "#.#..", ".###.", "..#.#", "####.", "..#.."},
+		'%':  {"##...", "##..#", "...#.", "..#..", ".#...", "#..##", "...##"},
+		'&':  {".##..", "#..#.", "#.#..", ".#...", "#.#.#", "#..#.", ".##.#"},
+		'\'': {"..#..", "..#..", ".....", ".....", ".....", ".....", "....."},
+		'(':  {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+		')':  {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+		'*':  {".....", "..#..", "#.#.#", ".###.", "#.#.#", "..#..", "....."},
+		'+':  {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+		',':  {".....", ".....", ".....", ".....", ".##..", "..#..", ".#..."},
+		'-':  {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+		'.':  {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+		'/':  {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+		':':  {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+		';':  {".....", ".##..", ".##..", ".....", ".##..", "..#..", ".#..."},
+		'<':  {"...#.", "..#..", ".#...", "#....", ".#...", "..#..", "...#."},
+		'=':  {".....", ".....", "#####", ".....", "#####", ".....", "....."},
+		'>':  {".#...", "..#..", "...#.", "....#", "...#.", "..#..", ".#..."},
+		'?':  {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+		'@':  {".###.", "#...#", "....#", ".##.#", "#.#.#", "#.#.#", ".###."},
+		'[':  {".###.", ".#...", ".#...", ".#...", ".#...", ".#...", ".###."},
+		'\\': {".....", "#....", ".#...", "..#..", "...#.", "....#", "....."},
+		']':  {".###.", "...#.", "...#.", "...#.", "...#.", "...#.", ".###."},
+		'^':  {"..#..", ".#.#.", "#...#", ".....", ".....", ".....", "....."},
+		'_':  {".....", ".....", ".....", ".....", ".....", ".....", "#####"},
+		'`':  {".#...", "..#..", ".....", ".....", ".....", ".....", "....."},
+		'{':  {"...#.", "..#..", "..#..", ".#...", "..#..", "..#..", "...#."},
+		'|':  {"..#..", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+		'}':  {".#...", "..#..", "..#..", "...#.", "..#..", "..#..", ".#..."},
+		'~':  {".....", ".....", ".#...", "#.#.#", "...#.", ".....", "....."},
+	},
+}
@@ -6,6 +6,7 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/dynamo/timer"
 )
 
 var (
@@ -21,10 +22,10 @@ var (
 
 // Animation is a set of frames that can be stepped and drawn
 type Animation struct {
-	Frames     []*ebiten.Image
-	Index      int
-	Delay      int
-	delayCount int
+	Frames []*ebiten.Image
+	Index  int
+	Delay  int // Ticks between frames
+	timer  *timer.Timer
 }
 
 // CurrentFrame returns an ebiten Image for the current frame
@@ -32,12 +33,18 @@ func (a *Animation) CurrentFrame() *ebiten.Image {
 	return a.Frames[a.Index]
 }
 
-// Update steps through frames with a delay
-func (a *Animation) Update() {
-	if a.delayCount == 0 {
-		a.nextFrame()
+// Start plays the animation, stepping through frames as the scheduler ticks
+func (a *Animation) Start(s *timer.Scheduler) {
+	a.Stop()
+	a.timer = s.Every(a.Delay, a.nextFrame)
+}
+
+// Stop pauses the animation on its current frame
+func (a *Animation) Stop() {
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
 	}
-	a.delayCount = (a.delayCount + 1) % a.Delay
 }
 
 // steps through frames
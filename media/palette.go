@@ -0,0 +1,41 @@
+package media
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// Palettes are the colour schemes the 1-bit screen can be shown in
+// Each one is a pair of the OFF colour followed by the ON colour.
+var Palettes map[string]color.Palette = map[string]color.Palette{
+	"nokia":   {color.RGBA{67, 82, 61, 255}, color.RGBA{199, 240, 216, 255}},
+	"mono":    {color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}},
+	"gameboy": {color.RGBA{15, 56, 15, 255}, color.RGBA{155, 188, 15, 255}},
+	"amber":   {color.RGBA{40, 24, 0, 255}, color.RGBA{255, 176, 0, 255}},
+	"blue":    {color.RGBA{22, 32, 86, 255}, color.RGBA{170, 210, 255, 255}},
+}
+
+// PaletteNames lists the names of all the Palettes in alphabetical order
+func PaletteNames() []string {
+	names := make([]string, 0, len(Palettes))
+	for name := range Palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetPalette changes the screen colours to one of the Palettes
+// Images made from the palette, like the title frames, have to be made again
+// afterwards to get the new colours.
+func SetPalette(name string) error {
+	p, ok := Palettes[name]
+	if !ok {
+		return fmt.Errorf("unknown palette %q, choose from %s", name, strings.Join(PaletteNames(), ", "))
+	}
+	ColorDark, ColorLight = p[0], p[1]
+	NokiaPalette = color.Palette{ColorDark, ColorLight}
+	return nil
+}
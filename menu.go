@@ -0,0 +1,147 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/sinisterstuf/dynamo/media"
+)
+
+// MenuLineHeight is how many pixels tall each line of a menu is
+const MenuLineHeight int = 6
+
+// MenuOverlayWidth is how many pixels wide menus drawn over the game are
+const MenuOverlayWidth int = 64
+
+// MenuItem is one line of a Menu
+type MenuItem struct {
+	Label    string
+	Action   func(g *Game)        // What happens when it's picked, nil for text
+	Value    func(g *Game) string // Optional setting shown on the right
+	Disabled func(g *Game) bool   // Optional check for greying it out
+}
+
+// Menu is a Nokia-style list of items with the selected one highlighted
+// It is navigated with W and S, E picks an item and A goes back.  Menus that
+// don't fit on the screen scroll to keep the selected item in view.
+type Menu struct {
+	Title   string
+	Items   []MenuItem
+	Index   int           // Which item is selected
+	Back    func(g *Game) // What happens when going back, nil to stay put
+	Overlay bool          // Draw in a box over the game instead of full screen
+	scroll  int           // Index of the first item shown on screen
+}
+
+// Update handles menu navigation for one tick
+func (m *Menu) Update(g *Game) {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyW):
+		m.move(g, -1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyS):
+		m.move(g, 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyE):
+		if m.selectable(g, m.Index) {
+			m.Items[m.Index].Action(g)
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyA):
+		if m.Back != nil {
+			m.Back(g)
+		}
+	}
+}
+
+// Draw draws the menu over the whole screen, or in a box if it's an overlay
+func (m *Menu) Draw(g *Game, screen *ebiten.Image) {
+	area := m.area()
+	if m.Overlay {
+		screen.SubImage(area.Inset(-1)).(*ebiten.Image).Fill(media.ColorDark)
+	}
+	screen.SubImage(area).(*ebiten.Image).Fill(media.ColorLight)
+	width := area.Dx()
+	media.DrawText(screen, m.Title, area.Min.X, area.Min.Y+1, &media.TextOptions{
+		Color: media.ColorDark,
+		Align: media.AlignCenter,
+		Width: width,
+	})
+
+	for i := m.scroll; i < len(m.Items) && i < m.scroll+m.rows(); i++ {
+		item := m.Items[i]
+		y := area.Min.Y + (i-m.scroll+1)*MenuLineHeight
+		selected := i == m.Index && m.selectable(g, i)
+		row := media.DrawText(screen, item.Label, area.Min.X, y+1, &media.TextOptions{
+			Color:    media.ColorDark,
+			Width:    width,
+			Inverted: selected,
+		})
+		fg := media.ColorDark
+		if selected {
+			fg = media.ColorLight
+		}
+		if item.Value != nil {
+			media.DrawText(screen, item.Value(g), area.Min.X, y+1, &media.TextOptions{
+				Color: fg,
+				Align: media.AlignRight,
+				Width: width,
+			})
+		}
+		if m.disabled(g, i) {
+			greyOut(screen, row, media.ColorLight)
+		}
+	}
+}
+
+// returns where on the screen the menu is drawn
+// Overlays are a box in the middle of the screen just big enough for them.
+func (m *Menu) area() image.Rectangle {
+	screen := image.Rectangle{Max: media.GameSize}
+	if !m.Overlay {
+		return screen
+	}
+	size := image.Pt(
+		MenuOverlayWidth,
+		min(len(m.Items)+1, screen.Dy()/MenuLineHeight-1)*MenuLineHeight+1,
+	)
+	corner := screen.Size().Sub(size).Div(2)
+	return image.Rectangle{corner, corner.Add(size)}
+}
+
+// returns how many items fit in the menu at once
+func (m *Menu) rows() int {
+	return m.area().Dy()/MenuLineHeight - 1
+}
+
+// Greys out an area by blanking every other pixel in a checkerboard pattern
+func greyOut(screen *ebiten.Image, area image.Rectangle, bg color.Color) {
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X + y%2; x < area.Max.X; x += 2 {
+			screen.Set(x, y, bg)
+		}
+	}
+}
+
+// moves the selection up or down, skipping lines that can't be picked
+func (m *Menu) move(g *Game, by int) {
+	rows := m.rows()
+	for i := m.Index + by; i >= 0 && i < len(m.Items); i += by {
+		if m.selectable(g, i) {
+			m.Index = i
+			m.scroll = max(min(m.scroll, m.Index), m.Index-rows+1)
+			return
+		}
+	}
+	// Nothing else to select, so just scroll through the text
+	m.scroll = max(0, min(m.scroll+by, len(m.Items)-rows))
+}
+
+// reports whether an item can be highlighted
+func (m *Menu) selectable(g *Game, i int) bool {
+	return m.Items[i].Action != nil && !m.disabled(g, i)
+}
+
+// reports whether an item is greyed out
+func (m *Menu) disabled(g *Game, i int) bool {
+	return m.Items[i].Disabled != nil && m.Items[i].Disabled(g)
+}
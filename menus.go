@@ -0,0 +1,309 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sinisterstuf/dynamo/media"
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// NewMainMenu makes the menu shown after the title screen
+func NewMainMenu() *Menu {
+	return &Menu{
+		Title: "DYNAMO",
+		Items: []MenuItem{
+			{Label: "New game", Action: func(g *Game) { g.NewGame(sim.LevelBeginner) }},
+			{Label: "Daily", Action: func(g *Game) { g.NewDaily() }},
+			{Label: "Endless", Action: func(g *Game) { g.NewEndless() }},
+			{
+				Label:    "Continue",
+				Action:   func(g *Game) { g.ContinueRun() },
+				Disabled: func(g *Game) bool { return !g.Started && !g.HasSave },
+			},
+			{Label: "Level select", Action: func(g *Game) { g.OpenMenu(NewLevelMenu()) }},
+			{Label: "Settings", Action: func(g *Game) { g.OpenMenu(NewSettingsMenu()) }},
+			{Label: "High scores", Action: func(g *Game) { g.OpenMenu(NewHighScoresMenu()) }},
+			{Label: "Help", Action: func(g *Game) { g.OpenMenu(NewHelpMenu()) }},
+			{Label: "Quit", Action: func(g *Game) { g.OpenMenu(NewQuitMenu(backToMain)) }},
+		},
+	}
+}
+
+// NewPauseMenu makes the menu shown over a paused level
+func NewPauseMenu() *Menu {
+	return &Menu{
+		Title:   "PAUSED",
+		Overlay: true,
+		Back:    func(g *Game) { g.Resume() },
+		Items: []MenuItem{
+			{Label: "Resume", Action: func(g *Game) { g.Resume() }},
+			{
+				Label: "Restart maze",
+				Action: func(g *Game) {
+					g.Pressed = g.Pressed.With(sim.ButtonRestart)
+					g.Resume()
+				},
+				// Replays already have their restarts recorded
+				Disabled: func(g *Game) bool { return g.Playback != nil },
+			},
+			{Label: "Main menu", Action: backToMain},
+			{Label: "Quit", Action: func(g *Game) {
+				g.Menu = NewQuitMenu(func(g *Game) { g.Pause() })
+				g.Menu.Overlay = true
+			}},
+		},
+	}
+}
+
+// NewResultsMenu makes the screen showing how the player did in a maze
+func NewResultsMenu(stats sim.Stats) *Menu {
+	return &Menu{
+		Title: fmt.Sprintf("DEPTH %d DONE", stats.Depth+1),
+		Index: 6,
+		Items: []MenuItem{
+			{Label: "Time", Value: func(g *Game) string {
+				return formatTicks(stats.Ticks) + "/" + formatTicks(stats.ParTicks())
+			}},
+			{Label: "Steps", Value: func(g *Game) string {
+				return fmt.Sprintf("%d/%d", stats.Steps, stats.Par)
+			}},
+			{Label: "Bumps", Value: func(g *Game) string { return strconv.Itoa(stats.Bumps) }},
+			{Label: "Torch", Value: func(g *Game) string { return fmt.Sprintf("%d%%", stats.TorchPercent()) }},
+			{Label: "Stars", Value: func(g *Game) string { return strings.Repeat("*", stats.Stars()) }},
+			{Label: "Score", Value: func(g *Game) string { return strconv.Itoa(stats.Score()) }},
+			{Label: "Next", Action: func(g *Game) {
+				next := func(g *Game) {
+					if !g.finishDaily() {
+						g.Pressed = g.Pressed.With(sim.ButtonNext)
+						g.enterLevel()
+					}
+				}
+				// Other modes only count whole runs towards their own tables
+				if g.Mode != ModeNormal {
+					next(g)
+					return
+				}
+				g.SubmitScore(LevelTable(stats.Level), stats.Score(), next)
+			}},
+		},
+	}
+}
+
+// NewQuitMenu makes a menu asking whether to really quit the game
+// Saying no or going back does whatever the back function says.
+func NewQuitMenu(back func(g *Game)) *Menu {
+	return &Menu{
+		Title: "QUIT?",
+		Back:  back,
+		Items: []MenuItem{
+			{Label: "No", Action: back},
+			{Label: "Yes", Action: func(g *Game) { g.Quit = true }},
+		},
+	}
+}
+
+// NewLevelMenu makes a menu for starting a new game at any difficulty level
+func NewLevelMenu() *Menu {
+	m := &Menu{Title: "LEVEL", Back: backToMain}
+	for level, name := range sim.LevelNames {
+		m.Items = append(m.Items, MenuItem{
+			Label:  name,
+			Action: func(g *Game) { g.NewGame(level) },
+		})
+	}
+	return m
+}
+
+// NewSettingsMenu makes a menu for changing game options
+// Picking a setting cycles through its possible values.
+func NewSettingsMenu() *Menu {
+	return &Menu{
+		Title: "SETTINGS",
+		Back:  backToMain,
+		Items: []MenuItem{
+			{
+				Label: "Maze",
+				Value: func(g *Game) string {
+					if g.Gen == nil {
+						return "auto"
+					}
+					return g.Gen.Name()
+				},
+				// Takes effect from the next run, changing it part way
+				// through would make the run impossible to replay
+				Action: func(g *Game) {
+					i := 0
+					for k, gen := range sim.Generators {
+						if gen == g.Gen {
+							i = k + 1
+						}
+					}
+					g.Gen = nil
+					if i < len(sim.Generators) {
+						g.Gen = sim.Generators[i]
+					}
+				},
+			},
+			{
+				Label: "Walls",
+				Value: func(g *Game) string {
+					if g.Shifting {
+						return "shifting"
+					}
+					return "still"
+				},
+				// Takes effect from the next run, it's part of what a run is
+				Action: func(g *Game) { g.Shifting = !g.Shifting },
+			},
+			{
+				Label: "Camera",
+				Value: func(g *Game) string {
+					if g.CameraMode == CameraFlip {
+						return "flip"
+					}
+					return "smooth"
+				},
+				Action: func(g *Game) {
+					g.CameraMode = (g.CameraMode + 1) % 2
+					if g.Camera != nil {
+						g.Camera.Mode = g.CameraMode
+					}
+				},
+			},
+		},
+	}
+}
+
+// NewHighScoresMenu makes a menu of the high score tables
+func NewHighScoresMenu() *Menu {
+	m := &Menu{Title: "HIGH SCORES", Back: backToMain}
+	for _, table := range HighScoreTables() {
+		m.Items = append(m.Items, MenuItem{
+			Label:  strings.ToUpper(table[:1]) + table[1:],
+			Action: func(g *Game) { g.OpenMenu(NewHighScoreTableMenu(g.Scores, table)) },
+		})
+	}
+	return m
+}
+
+// NewHighScoreTableMenu makes a page listing the scores in one table
+func NewHighScoreTableMenu(scores *HighScores, table string) *Menu {
+	m := &Menu{
+		Title: strings.ToUpper(table),
+		Back:  func(g *Game) { g.OpenMenu(NewHighScoresMenu()) },
+	}
+	for i, s := range scores.Tables[table] {
+		m.Items = append(m.Items, MenuItem{
+			Label: fmt.Sprintf("%d %s", i+1, s.Initials),
+			Value: func(g *Game) string { return strconv.Itoa(s.Score) },
+		})
+	}
+	if len(m.Items) == 0 {
+		m.Items = []MenuItem{{Label: "No scores yet"}}
+	}
+	return m
+}
+
+// NewHelpMenu makes a page explaining how to play
+func NewHelpMenu() *Menu {
+	return textPage("HELP", `WASD   move
+E      torch/stairs
+R+F    crank dynamo
+H      hint
+P      pause
+Q      quit
+Find the exit at the bottom of the maze, on the lowest floor. Keys open locked doors and switches open gates. Portals take you to their twin. Shifting walls move on a beat, or when the torch goes on. The minotaur hunts your torch light!`)
+}
+
+// makes a menu of plain text lines that can only be scrolled through
+// The text is word wrapped to fit on the screen.
+func textPage(title, text string) *Menu {
+	m := &Menu{Title: title, Back: backToMain}
+	for _, line := range media.WrapText(text, media.Font3x5, media.GameSize.X) {
+		m.Items = append(m.Items, MenuItem{Label: line})
+	}
+	return m
+}
+
+// goes back to the main menu from a sub-menu
+func backToMain(g *Game) {
+	g.OpenMenu(NewMainMenu())
+}
+
+// OpenMenu shows a menu on the screen
+func (g *Game) OpenMenu(m *Menu) {
+	g.Menu = m
+	g.State = StateMenu
+}
+
+// Pause freezes the current level and shows the pause menu over it
+func (g *Game) Pause() {
+	g.Menu = NewPauseMenu()
+	g.State = StatePaused
+}
+
+// ShowResults shows how the player did in the maze they just walked out of
+func (g *Game) ShowResults() {
+	g.Menu = NewResultsMenu(g.Sim.History[len(g.Sim.History)-1])
+	g.State = StateResults
+}
+
+// SubmitScore asks for initials if a score makes it into a high score table
+// Once it's stored, or if it isn't good enough, the game goes on with then.
+// Replays don't count, they're somebody's run that already happened.
+func (g *Game) SubmitScore(table string, score int, then func(g *Game)) {
+	if g.Playback != nil || !g.Scores.Qualifies(table, score) {
+		then(g)
+		return
+	}
+	g.Entry = NewInitialsEntry(score, g.Initials, func(g *Game, initials string) {
+		g.Initials = initials
+		g.Scores.Add(table, HighScore{
+			Initials: initials,
+			Score:    score,
+			Seed:     g.Sim.Seed,
+			When:     time.Now(),
+		})
+		if err := g.Scores.Save(); err != nil {
+			log.Println("saving high scores:", err)
+		}
+		then(g)
+	})
+	g.State = StateInitials
+}
+
+// Resume carries on with the level from where it was paused
+func (g *Game) Resume() {
+	g.enterLevel()
+}
+
+// NewGame starts a new run of the game from a difficulty level
+func (g *Game) NewGame(level int) {
+	seed := time.Now().UnixNano()
+	if g.FixedSeed {
+		seed = g.Seed
+	}
+	g.startRun(sim.New(seed, level, g.Gen, g.Shifting), ModeNormal)
+}
+
+// starts playing a run of the game from its first maze
+// Any saved run is thrown away, only the latest one can be continued.
+func (g *Game) startRun(s *sim.Sim, mode Mode) {
+	if g.HasSave {
+		RemoveSave()
+		g.HasSave = false
+	}
+	g.Sim = s
+	g.Mode = mode
+	g.Recording = sim.NewReplay(g.Sim)
+	g.Playback = nil
+	g.Pressed = 0
+	g.View = nil
+	g.SyncView()
+	g.Started = true
+	g.enterLevel()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sinisterstuf/dynamo/media"
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// MaxWindowScale is the biggest the window can be scaled up from the screen
+const MaxWindowScale int = 30
+
+// Options are the settings the game can be started with on the command line
+type Options struct {
+	Seed        int64         // Seed for new games, if FixedSeed is set
+	FixedSeed   bool          // Whether every new game uses Seed
+	Level       int           // Level to start at
+	StartGame   bool          // Whether to go straight into a game at Level
+	Scale       int           // How many window pixels each screen pixel is
+	Fullscreen  bool          // Whether to start in fullscreen
+	Gen         sim.Generator // Maze algorithm, or nil to pick one per level
+	Shifting    bool          // Whether walls shift in new runs
+	SkipTitle   bool          // Whether to go straight to the main menu
+	Daily       bool          // Whether to go straight into today's daily challenge
+	Palette     string        // Name of one of the media Palettes
+	Record      string        // File to write a replay of the last run to
+	Replay      string        // Replay file to play instead of a new game
+	FastForward int           // Ticks per frame when fast-forwarding a replay
+}
+
+// ParseOptions reads the game's options from command line arguments
+// Giving a seed or a level starts a game straight away, so testers can jump
+// to a specific maze. Invalid options are reported as errors that say why.
+func ParseOptions(args []string) (*Options, error) {
+	opts := &Options{}
+	var level, maze string
+	fs := flag.NewFlagSet("dynamo", flag.ContinueOnError)
+	fs.Int64Var(&opts.Seed, "seed", 0, "random seed for the mazes, the same seed always makes the same mazes")
+	fs.StringVar(&level, "level", "", "level to start at, by name or number: "+levelChoices())
+	fs.IntVar(&opts.Scale, "scale", 10, "window scale, how big each screen pixel is")
+	fs.BoolVar(&opts.Fullscreen, "fullscreen", false, "start in fullscreen")
+	fs.StringVar(&maze, "maze", "auto", "maze algorithm, auto picks one per level: "+mazeChoices())
+	fs.BoolVar(&opts.Shifting, "shifting", false, "play with walls that open and shut")
+	fs.BoolVar(&opts.SkipTitle, "skip-title", false, "skip the title animation and go to the main menu")
+	fs.BoolVar(&opts.Daily, "daily", false, "play today's daily challenge")
+	fs.StringVar(&opts.Palette, "palette", "nokia", "screen colours: "+strings.Join(media.PaletteNames(), ", "))
+	fs.StringVar(&opts.Record, "record", "", "write a replay of the last run to this file on exit")
+	fs.StringVar(&opts.Replay, "replay", "", "play back a replay file instead of starting at the title")
+	fs.IntVar(&opts.FastForward, "ff", 8, "ticks per frame when fast-forwarding a replay with space")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "seed":
+			opts.FixedSeed = true
+			opts.StartGame = true
+		case "level":
+			opts.StartGame = true
+		}
+	})
+
+	var err error
+	if level != "" {
+		if opts.Level, err = parseLevel(level); err != nil {
+			return nil, err
+		}
+	}
+	if maze != "auto" {
+		if opts.Gen = sim.GeneratorByName(maze); opts.Gen == nil {
+			return nil, fmt.Errorf("-maze: unknown algorithm %q, choose from %s", maze, mazeChoices())
+		}
+	}
+	if opts.Scale < 1 || opts.Scale > MaxWindowScale {
+		return nil, fmt.Errorf("-scale: must be from 1 to %d, not %d", MaxWindowScale, opts.Scale)
+	}
+	if _, ok := media.Palettes[opts.Palette]; !ok {
+		return nil, fmt.Errorf("-palette: unknown palette %q, choose from %s",
+			opts.Palette, strings.Join(media.PaletteNames(), ", "))
+	}
+	if opts.FastForward < 1 {
+		return nil, fmt.Errorf("-ff: must be at least 1, not %d", opts.FastForward)
+	}
+	if opts.Replay != "" && (opts.StartGame || opts.Gen != nil || opts.Shifting) {
+		return nil, errors.New("-replay: the replay already has a seed, level and maze, don't give -seed, -level, -maze or -shifting too")
+	}
+	if opts.Daily && (opts.Replay != "" || opts.StartGame || opts.Gen != nil || opts.Shifting) {
+		return nil, errors.New("-daily: the daily challenge has its own mazes, don't give -seed, -level, -maze, -shifting or -replay too")
+	}
+	return opts, nil
+}
+
+// reads a level from its name or number
+func parseLevel(s string) (int, error) {
+	for level, name := range sim.LevelNames {
+		if strings.EqualFold(s, name) {
+			return level, nil
+		}
+	}
+	level, err := strconv.Atoi(s)
+	if err != nil || level < 0 || level >= len(sim.LevelNames) {
+		return 0, fmt.Errorf("-level: unknown level %q, choose from %s", s, levelChoices())
+	}
+	return level, nil
+}
+
+// lists the levels that can be chosen
+func levelChoices() string {
+	var choices []string
+	for level, name := range sim.LevelNames {
+		choices = append(choices, fmt.Sprintf("%d/%s", level, strings.ToLower(name)))
+	}
+	return strings.Join(choices, ", ")
+}
+
+// lists the maze algorithms that can be chosen
+func mazeChoices() string {
+	choices := []string{"auto"}
+	for _, gen := range sim.Generators {
+		choices = append(choices, gen.Name())
+	}
+	return strings.Join(choices, ", ")
+}
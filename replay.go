@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// FastForward is how many ticks are played per frame while fast-forwarding
+// It can be changed with the -ff option.
+var FastForward int = 8
+
+// NextInput gets the simulation input for the next tick
+// During a replay it comes from the recording and is false once that's over,
+// otherwise it's read from the keyboard and added to the current recording.
+func (g *Game) NextInput() (sim.Input, bool) {
+	if g.Playback != nil {
+		return g.Playback.Next()
+	}
+	in := ReadInput()
+	g.Held &= in
+	in &^= g.Held
+	in |= g.Pressed
+	g.Pressed = 0
+	if g.Recording != nil {
+		g.Recording.Record(in)
+	}
+	return in, true
+}
+
+// PlayReplay starts playing back a recorded run instead of a new game
+func (g *Game) PlayReplay(r *sim.Replay) {
+	g.Sim = r.Sim()
+	g.Recording = nil
+	g.Playback = r.Play()
+	g.View = nil
+	g.SyncView()
+	g.Started = false
+	g.enterLevel()
+}
+
+// LoadReplay reads a replay file from disk
+func LoadReplay(path string) (*sim.Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := sim.ReadReplay(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// SaveReplay writes a recorded run to a replay file on disk
+func SaveReplay(path string, r *sim.Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
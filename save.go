@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// SaveVersion is the version of the save file that gets written
+const SaveVersion int = 2
+
+// SaveGame is a run in progress, saved on quitting to carry on with later
+type SaveGame struct {
+	Version int           `json:"version"`
+	Mode    Mode          `json:"mode"`
+	Date    time.Time     `json:"date,omitempty"` // Day of the daily challenge
+	Sim     *sim.Snapshot `json:"sim"`
+}
+
+// saveMigrations upgrade older save files to the next version, by the version
+// they upgrade from, so a save from any version can be brought up to date
+var saveMigrations = map[int]func(save map[string]any) error{
+	// Version 2 added hints, which older saves get the maze's full share of
+	1: func(save map[string]any) error {
+		s, ok := save["sim"].(map[string]any)
+		if !ok {
+			return errors.New("save has no game in it")
+		}
+		depth, _ := s["depth"].(float64)
+		s["hintsLeft"] = sim.Difficulties.At(min(int(depth), sim.LevelExtreme)).Hints
+		return nil
+	},
+}
+
+// LoadSave reads the saved run from the config directory
+// It's nil if there isn't one.
+func LoadSave() (*SaveGame, error) {
+	path, err := ConfigPath("save.json")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	save, err := decodeSave(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return save, nil
+}
+
+// reads a save file, upgrading it from older versions first
+func decodeSave(data []byte) (*SaveGame, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	version, ok := raw["version"].(float64)
+	if !ok {
+		return nil, errors.New("save has no version")
+	}
+	for v := int(version); v < SaveVersion; v++ {
+		migrate, ok := saveMigrations[v]
+		if !ok {
+			return nil, fmt.Errorf("can't upgrade save from version %d", v)
+		}
+		if err := migrate(raw); err != nil {
+			return nil, fmt.Errorf("upgrading save from version %d: %w", v, err)
+		}
+		raw["version"] = float64(v + 1)
+	}
+	if int(version) > SaveVersion {
+		return nil, fmt.Errorf("save is from a newer version %d", int(version))
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	save := &SaveGame{}
+	if err := json.Unmarshal(data, save); err != nil {
+		return nil, err
+	}
+	if save.Sim == nil {
+		return nil, errors.New("save has no game in it")
+	}
+	return save, nil
+}
+
+// WriteSave writes a run to the config directory, replacing any other one
+func WriteSave(save *SaveGame) error {
+	path, err := ConfigPath("save.json")
+	if err != nil {
+		return err
+	}
+	save.Version = SaveVersion
+	data, err := json.Marshal(save)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data)
+}
+
+// RemoveSave deletes the saved run, e.g. once it's been carried on with
+func RemoveSave() {
+	path, err := ConfigPath("save.json")
+	if err == nil {
+		err = os.Remove(path)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Println("removing save:", err)
+	}
+}
+
+// SaveRun saves the run in progress so it can be continued next time
+func (g *Game) SaveRun() {
+	if !g.Started || g.Playback != nil {
+		return
+	}
+	save := &SaveGame{Mode: g.Mode, Date: g.Date, Sim: g.Sim.Snapshot()}
+	if err := WriteSave(save); err != nil {
+		log.Println("saving game:", err)
+	}
+}
+
+// ContinueRun carries on with the run in progress, loading it if it was saved
+// A save that can't be loaded is thrown away and the game goes back to the
+// title screen.
+func (g *Game) ContinueRun() {
+	if g.Sim != nil && g.Started {
+		g.enterLevel()
+		return
+	}
+	save, err := LoadSave()
+	if err == nil && save == nil {
+		err = errors.New("no saved game")
+	}
+	if err == nil && save.Mode == ModeDaily {
+		err = checkDaily(save)
+	}
+	var s *sim.Sim
+	if err == nil {
+		s, err = sim.Restore(save.Sim)
+	}
+	g.HasSave = false
+	RemoveSave()
+	if err != nil {
+		log.Println("loading save:", err)
+		g.State = StateTitle
+		g.Title.Start(g.Clock)
+		return
+	}
+
+	g.Sim = s
+	g.Mode = save.Mode
+	g.Date = save.Date
+	g.Recording = nil // the start of the run wasn't recorded this time
+	g.Playback = nil
+	g.Pressed = 0
+	g.View = nil
+	g.SyncView()
+	g.Started = true
+	g.enterLevel()
+	if s.Done {
+		g.ShowResults()
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sinisterstuf/dynamo/sim"
+)
+
+// HighScoresVersion is the version of the high score file that gets written
+const HighScoresVersion int = 1
+
+// HighScoreEntries is how many scores each table keeps
+const HighScoreEntries int = 5
+
+// DailyTable is the name of the high score table for daily challenges
+const DailyTable string = "daily"
+
+// HighScore is one line of a high score table
+type HighScore struct {
+	Initials string    `json:"initials"`
+	Score    int       `json:"score"`
+	Seed     int64     `json:"seed"`
+	When     time.Time `json:"when"`
+}
+
+// HighScores are the best scores, in tables for each level and game mode
+type HighScores struct {
+	Version int                    `json:"version"`
+	Tables  map[string][]HighScore `json:"tables"`
+}
+
+// LevelTable is the name of the high score table for mazes at a level
+func LevelTable(level int) string {
+	return strings.ToLower(sim.LevelNames[level])
+}
+
+// HighScoreTables lists the names of all the tables in the order they're shown
+func HighScoreTables() []string {
+	var tables []string
+	for level := range sim.LevelNames {
+		tables = append(tables, LevelTable(level))
+	}
+	return append(tables, DailyTable, EndlessTable)
+}
+
+// LoadHighScores reads the high score tables from the config directory
+// There being no file yet just means there are no scores yet.
+func LoadHighScores() (*HighScores, error) {
+	scores := &HighScores{Version: HighScoresVersion, Tables: map[string][]HighScore{}}
+	path, err := ConfigPath("scores.json")
+	if err != nil {
+		return scores, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return scores, nil
+	}
+	if err != nil {
+		return scores, err
+	}
+	loaded := &HighScores{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return scores, fmt.Errorf("%s: %w", path, err)
+	}
+	if loaded.Version > HighScoresVersion {
+		return scores, fmt.Errorf("%s: high scores are from a newer version %d", path, loaded.Version)
+	}
+	if loaded.Tables != nil {
+		scores.Tables = loaded.Tables
+	}
+	return scores, nil
+}
+
+// Save writes the high score tables to the config directory
+func (h *HighScores) Save() error {
+	path, err := ConfigPath("scores.json")
+	if err != nil {
+		return err
+	}
+	h.Version = HighScoresVersion
+	data, err := json.MarshalIndent(h, "", "\t")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data)
+}
+
+// Qualifies reports whether a score is good enough to go in a table
+func (h *HighScores) Qualifies(table string, score int) bool {
+	entries := h.Tables[table]
+	return score > 0 && (len(entries) < HighScoreEntries || score > entries[len(entries)-1].Score)
+}
+
+// Add puts a score in its place in a table, dropping the lowest if it's full
+func (h *HighScores) Add(table string, score HighScore) {
+	entries := append(h.Tables[table], score)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Score > entries[j].Score })
+	h.Tables[table] = entries[:min(len(entries), HighScoreEntries)]
+}
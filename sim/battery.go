@@ -0,0 +1,67 @@
+package sim
+
+// BatteryCapacity is how much charge a full torch battery holds
+const BatteryCapacity int = 3000
+
+// Battery is the torch's energy store, recharged by cranking the dynamo
+type Battery struct {
+	Charge   int    // Current charge, between 0 and Capacity
+	Capacity int    // Maximum charge the battery can hold
+	Drain    int    // Charge lost every tick that the torch is lit
+	Yield    int    // Charge gained by one full turn of the crank
+	lastKey  Button // Which crank button was pressed last
+	held     int    // How many ticks the crank button has been held for
+}
+
+// NewBattery initialises a fully charged Battery for a difficulty
+func NewBattery(d Difficulty) *Battery {
+	return &Battery{
+		Charge:   BatteryCapacity,
+		Capacity: BatteryCapacity,
+		Drain:    d.Drain,
+		Yield:    d.Yield,
+		lastKey:  -1,
+	}
+}
+
+// Crank charges the battery from a turn of the dynamo handle
+// Alternating between crank keys gives a full turn's worth of charge, tapping
+// the same key again only gives a little and holding a key down keeps
+// generating with diminishing returns until it gives nothing at all.
+func (b *Battery) Crank(key Button, justPressed bool) {
+	if justPressed {
+		if key != b.lastKey {
+			b.add(b.Yield)
+		} else {
+			b.add(b.Yield / 4)
+		}
+		b.lastKey = key
+		b.held = 0
+		return
+	}
+	b.held++
+	b.add(b.Yield / (20 + b.held))
+}
+
+// Discharge drains one tick's worth of charge and reports whether any is left
+func (b *Battery) Discharge() bool {
+	b.Charge -= b.Drain
+	if b.Charge <= 0 {
+		b.Charge = 0
+		return false
+	}
+	return true
+}
+
+// Empty reports whether the battery has no charge left
+func (b *Battery) Empty() bool {
+	return b.Charge == 0
+}
+
+// adds charge without overflowing the battery's capacity
+func (b *Battery) add(charge int) {
+	b.Charge += charge
+	if b.Charge > b.Capacity {
+		b.Charge = b.Capacity
+	}
+}
@@ -0,0 +1,111 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+)
+
+// Bot plays the game by itself, through the same Input a player gives
+// It takes the shortest way out, but now and then it stops at a junction to
+// look around with the torch, and it cranks the dynamo when the battery runs
+// low, so it looks like somebody playing.
+type Bot struct {
+	rng      *rand.Rand
+	ticks    int
+	looking  int         // Ticks left of standing still with the torch on
+	cranking bool        // Whether it's busy charging the battery
+	at       image.Point // Cell it last worked out the way out from
+	next     image.Point // Next step of the way out from there
+	last     Input       // Buttons it held on the last tick
+}
+
+// NewBot makes a Bot whose habits are random but repeatable from a seed
+func NewBot(seed int64) *Bot {
+	return &Bot{rng: rand.New(rand.NewSource(seed)), at: image.Pt(-1, -1)}
+}
+
+// Input decides which buttons to hold for the next tick of a simulation
+func (b *Bot) Input(s *Sim) Input {
+	b.ticks++
+	b.last = b.decide(s)
+	return b.last
+}
+
+// works out what to press, one tap or held button at a time
+func (b *Bot) decide(s *Sim) Input {
+	var in Input
+	switch {
+	case s.Done:
+		if !b.last.Held(ButtonNext) {
+			in = in.With(ButtonNext)
+		}
+		return in
+	case s.Win:
+		return in
+	}
+
+	// Crank until the battery's mostly full, alternating every few ticks
+	battery := s.Player.Battery
+	if battery.Charge*4 < battery.Capacity || (b.cranking && battery.Charge*5 < battery.Capacity*4) {
+		b.cranking = true
+		if (b.ticks/3)%2 == 0 {
+			return in.With(ButtonCrankA)
+		}
+		return in.With(ButtonCrankB)
+	}
+	b.cranking = false
+
+	// Stand still and shine the torch around for a bit
+	if b.looking > 0 {
+		b.looking--
+		if !s.Player.TorchOn && !b.last.Held(ButtonTorch) {
+			in = in.With(ButtonTorch)
+		}
+		return in
+	}
+
+	// Think again when it moves, or when a wall shifts into its way
+	coords := s.Player.Coords
+	if coords != b.at || !s.Maze.Passable(b.next) {
+		b.at = coords
+		path := s.Maze.Solution(coords)
+		if len(path) == 0 {
+			return in
+		}
+		b.next = path[0]
+		// Switching the torch on while standing on stairs would take them
+		_, stairs := s.Maze.Link(coords)
+		if !stairs && b.junction(s.Maze, coords) && b.rng.Intn(3) == 0 {
+			b.looking = 20 + b.rng.Intn(40)
+			return in
+		}
+	}
+	if to, ok := s.Maze.Link(coords); ok && to == b.next {
+		if !b.last.Held(ButtonTorch) {
+			in = in.With(ButtonTorch)
+		}
+		return in
+	}
+	for _, m := range moves {
+		if s.Maze.Arrive(coords.Add(m.dir)) == b.next {
+			// A fresh player only moves on a fresh press, e.g. after
+			// starting over, so let go for a tick first
+			if !s.Player.Moved && b.last.Held(m.button) {
+				return in
+			}
+			return in.With(m.button)
+		}
+	}
+	return in
+}
+
+// reports whether a cell has more than two ways to go from it
+func (b *Bot) junction(maze *Maze, p image.Point) bool {
+	ways := 0
+	for _, d := range directions {
+		if maze.Open(p.Add(d)) {
+			ways++
+		}
+	}
+	return ways > 2
+}
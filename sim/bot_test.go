@@ -0,0 +1,60 @@
+package sim
+
+import (
+	"image"
+	"testing"
+)
+
+func TestBotGetsOutOfMazes(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		s := New(seed, LevelBeginner, nil, false)
+		bot := NewBot(seed)
+		for range 5000 {
+			s.Step(bot.Input(s))
+		}
+		if s.Depth < LevelMedium {
+			t.Errorf("seed %d: only got to depth %d", seed, s.Depth)
+		}
+	}
+}
+
+func TestBotCarriesOnAfterRestarting(t *testing.T) {
+	s := New(1, LevelBeginner, nil, false)
+	bot := NewBot(1)
+	start := s.Player.Coords
+	for range 1000 {
+		s.Step(bot.Input(s))
+		if s.Player.Coords != start {
+			break
+		}
+	}
+	// Caught, say, while still holding the button it was moving with
+	s.RestartLevel()
+	for range 100 {
+		s.Step(bot.Input(s))
+	}
+	if s.Player.Coords == start {
+		t.Errorf("stuck at the start %v after restarting", start)
+	}
+}
+
+func TestBotWaitsForNextAfterWinning(t *testing.T) {
+	s := New(1, LevelBeginner, nil, false)
+	bot := NewBot(1)
+	for range 5000 {
+		s.Step(bot.Input(s))
+		if s.Win {
+			break
+		}
+	}
+	if !s.Win {
+		t.Fatal("didn't get out")
+	}
+	for !s.Done {
+		s.Step(bot.Input(s))
+	}
+	s.Step(bot.Input(s))
+	if s.Depth != LevelBeginner+1 || s.Player.Coords != image.Pt(1, 1) {
+		t.Errorf("at depth %d, want to have gone on to the next maze", s.Depth)
+	}
+}
@@ -0,0 +1,104 @@
+package sim
+
+import "image"
+
+// Difficulty describes everything that makes one maze harder than another
+type Difficulty struct {
+	Size        image.Point // Maze size in cells, which can be bigger than the screen
+	Braid       int         // Percentage of dead ends knocked through into loops
+	Drain       int         // Torch battery charge lost per lit tick
+	Yield       int         // Torch battery charge gained per crank turn
+	Range       int         // Torch light radius on a full battery
+	Generator   Generator   // Default maze algorithm for the maze
+	Enemies     int         // How many minotaurs hunt the player
+	EnemySpeed  int         // Ticks between minotaur steps, lower is faster
+	TimePerStep int         // Time limit in ticks per step of par, 0 for none
+	Hints       int         // How many hints the player gets in the maze
+	Doors       int         // How many locked doors there are, each with a key
+	Gates       int         // How many gates there are, each with a switch
+	Shifters    int         // How many walls can shift when they're shifting
+	Floors      int         // How many floors the maze has, each the full Size
+	Portals     int         // How many pairs of portals there are
+}
+
+// numbers returns the parts of the Difficulty that ramp up and down smoothly
+func (d *Difficulty) numbers() []*int {
+	return []*int{
+		&d.Size.X, &d.Size.Y, &d.Braid, &d.Drain, &d.Yield, &d.Range,
+		&d.Enemies, &d.EnemySpeed, &d.TimePerStep, &d.Hints, &d.Doors, &d.Gates,
+		&d.Shifters, &d.Floors, &d.Portals,
+	}
+}
+
+// Keyframe is the difficulty at a certain depth into the game
+type Keyframe struct {
+	Depth int
+	Difficulty
+}
+
+// Curve describes how the difficulty ramps up the deeper into the game it is
+// Between keyframes each number goes smoothly from one to the next, and the
+// maze algorithm changes at each keyframe.  Past the last keyframe the numbers
+// keep going the same way they were going between the last two, until they
+// reach the Limit.
+type Curve struct {
+	Keyframes []Keyframe
+	Limit     Difficulty
+}
+
+// At works out the difficulty at a depth into the game
+func (c Curve) At(depth int) Difficulty {
+	depth = max(depth, 0)
+	i := 1
+	for i < len(c.Keyframes)-1 && c.Keyframes[i].Depth < depth {
+		i++
+	}
+	from, to := c.Keyframes[i-1], c.Keyframes[i]
+	d := from.Difficulty
+	if depth >= to.Depth {
+		d.Generator = to.Generator
+	}
+
+	limit := c.Limit
+	nd, nf, nt, nl := d.numbers(), from.numbers(), to.numbers(), limit.numbers()
+	for k := range nd {
+		*nd[k] = *nf[k] + (*nt[k]-*nf[k])*(depth-from.Depth)/(to.Depth-from.Depth)
+		if depth > to.Depth {
+			// Keep heading towards the limit, but no further
+			lo, hi := min(*nt[k], *nl[k]), max(*nt[k], *nl[k])
+			*nd[k] = max(lo, min(*nd[k], hi))
+		}
+	}
+	return d
+}
+
+// Difficulties is the curve the game's difficulty follows
+// The first keyframes are the named levels, after which endless mode keeps
+// going with a time limit to get through each maze in.
+var Difficulties Curve = Curve{
+	Keyframes: []Keyframe{
+		{0, Difficulty{Size: image.Pt(7, 3), Braid: 0, Drain: 1, Yield: 150, Range: 12, Generator: Kruskal{}, Enemies: 0, EnemySpeed: 12, TimePerStep: 0, Hints: 3, Doors: 0, Gates: 0, Shifters: 2, Floors: 1, Portals: 0}},
+		{1, Difficulty{Size: image.Pt(13, 7), Braid: 10, Drain: 2, Yield: 120, Range: 10, Generator: BinaryTree{}, Enemies: 1, EnemySpeed: 12, TimePerStep: 0, Hints: 3, Doors: 0, Gates: 1, Shifters: 4, Floors: 1, Portals: 0}},
+		{2, Difficulty{Size: image.Pt(20, 11), Braid: 20, Drain: 2, Yield: 100, Range: 9, Generator: Prim{}, Enemies: 1, EnemySpeed: 10, TimePerStep: 0, Hints: 2, Doors: 1, Gates: 0, Shifters: 8, Floors: 1, Portals: 1}},
+		{3, Difficulty{Size: image.Pt(41, 23), Braid: 30, Drain: 3, Yield: 80, Range: 8, Generator: Backtracker{}, Enemies: 2, EnemySpeed: 8, TimePerStep: 0, Hints: 2, Doors: 1, Gates: 1, Shifters: 16, Floors: 1, Portals: 1}},
+		{4, Difficulty{Size: image.Pt(63, 35), Braid: 40, Drain: 4, Yield: 60, Range: 7, Generator: Wilson{}, Enemies: 3, EnemySpeed: 6, TimePerStep: 0, Hints: 1, Doors: 2, Gates: 1, Shifters: 24, Floors: 2, Portals: 2}},
+		{5, Difficulty{Size: image.Pt(63, 35), Braid: 40, Drain: 4, Yield: 60, Range: 7, Generator: Wilson{}, Enemies: 3, EnemySpeed: 6, TimePerStep: 20, Hints: 1, Doors: 2, Gates: 1, Shifters: 24, Floors: 2, Portals: 2}},
+		{10, Difficulty{Size: image.Pt(100, 56), Braid: 50, Drain: 5, Yield: 50, Range: 6, Generator: GrowingTree{}, Enemies: 4, EnemySpeed: 5, TimePerStep: 14, Hints: 1, Doors: 2, Gates: 2, Shifters: 40, Floors: 3, Portals: 3}},
+		{20, Difficulty{Size: image.Pt(150, 84), Braid: 60, Drain: 6, Yield: 40, Range: 5, Generator: Eller{}, Enemies: 6, EnemySpeed: 4, TimePerStep: 10, Hints: 0, Doors: 3, Gates: 3, Shifters: 60, Floors: 4, Portals: 4}},
+	},
+	Limit: Difficulty{Size: image.Pt(200, 112), Braid: 80, Drain: 10, Yield: 20, Range: 4, Enemies: 12, EnemySpeed: 2, TimePerStep: 6, Hints: 0, Doors: 4, Gates: 4, Shifters: 100, Floors: 6, Portals: 6},
+}
+
+// Levels represent the difficulty of different game levels
+// Each is the depth into the game that it starts at.  Outside of endless mode
+// the game stays at the difficulty of LevelExtreme after getting there.
+const (
+	LevelBeginner int = iota
+	LevelEasy
+	LevelMedium
+	LevelHard
+	LevelExtreme
+)
+
+// LevelNames are what the difficulty levels are called, in order
+var LevelNames []string = []string{"Beginner", "Easy", "Medium", "Hard", "Extreme"}
@@ -0,0 +1,136 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+
+	"github.com/sinisterstuf/dynamo/timer"
+)
+
+// Enemy is a minotaur that roams the maze hunting for the Player
+// It wanders around at random until it sees the light of the Player's torch,
+// then it chases after it.  Its logic only depends on the maze Grid so that it
+// can be tested without running the game.
+type Enemy struct {
+	Coords  image.Point
+	Start   image.Point     // Where it comes back to when the level restarts
+	Path    []image.Point   // Route it is following, next step first
+	Chasing bool            // Whether it is after the Player or just wandering
+	Speed   int             // Ticks between steps, lower is faster
+	Area    image.Rectangle // Part of the grid it wanders around, its floor of the maze
+	step    *timer.Timer    // Cooldown until the next step
+	rng     *rand.Rand
+}
+
+// NewEnemy makes an Enemy that takes a step every few ticks of a level clock
+func NewEnemy(coords image.Point, speed int, source rand.Source, clock *timer.Scheduler) *Enemy {
+	e := &Enemy{
+		Coords: coords,
+		Start:  coords,
+		Speed:  speed,
+		step:   clock.Cooldown(),
+		rng:    rand.New(source),
+	}
+	e.step.Reset(speed)
+	return e
+}
+
+// SpawnEnemies places enemies on each floor of the maze in turn
+// On the floor with the given point they go in the far half of it away from
+// the point, on other floors they can go anywhere.  Enemies get faster and
+// more numerous with the difficulty.
+func SpawnEnemies(grid *Grid, from image.Point, floors []image.Rectangle, d Difficulty, source rand.Source, clock *timer.Scheduler) []*Enemy {
+	count := d.Enemies
+	if count == 0 {
+		return nil
+	}
+	dist := grid.Distances(from)
+	furthest := 0
+	for _, d := range dist {
+		furthest = max(furthest, d)
+	}
+	home := 0
+	spots := make([][]image.Point, len(floors))
+	for f, area := range floors {
+		if from.In(area) {
+			home = f
+		}
+	}
+	for k, d := range dist {
+		p := image.Pt(k%grid.Size.X, k/grid.Size.X)
+		for f, area := range floors {
+			if p.In(area) && (f == home && d*2 >= furthest || f != home && !grid.Walls[k]) {
+				spots[f] = append(spots[f], p)
+			}
+		}
+	}
+
+	r := rand.New(source)
+	enemies := make([]*Enemy, count)
+	for i := range enemies {
+		f := i % len(floors)
+		if len(spots[f]) == 0 {
+			f = home
+		}
+		enemies[i] = NewEnemy(spots[f][r.Intn(len(spots[f]))], d.EnemySpeed, source, clock)
+		enemies[i].Area = floors[f]
+	}
+	return enemies
+}
+
+// Update moves the Enemy by one tick
+// The Enemy chases the player when it can see their torch is on, otherwise it
+// keeps going to where it last saw them and then goes back to wandering.
+func (e *Enemy) Update(grid *Grid, player image.Point, torchOn bool) {
+	if torchOn && grid.Visible(e.Coords, player) {
+		e.Chasing = true
+		if len(e.Path) == 0 || e.Path[len(e.Path)-1] != player {
+			e.Path = grid.Path(e.Coords, player)
+		}
+	}
+
+	if len(e.Path) == 0 {
+		e.Chasing = false
+		e.Path = grid.Path(e.Coords, e.randomCell(grid))
+	}
+
+	if e.step.Active() {
+		return
+	}
+	e.step.Reset(e.Speed)
+	if len(e.Path) > 0 && grid.Wall(e.Path[0]) {
+		e.Path = nil // A wall shifted into the way, think again next tick
+		return
+	}
+	if len(e.Path) > 0 {
+		e.Coords = e.Path[0]
+		e.Path = e.Path[1:]
+	}
+}
+
+// Caught reports whether the Enemy has got the Player
+func (e *Enemy) Caught(player image.Point) bool {
+	return e.Coords == player
+}
+
+// Reset puts the Enemy back where it started, e.g. when the level restarts
+func (e *Enemy) Reset() {
+	e.Coords = e.Start
+	e.Path = nil
+	e.Chasing = false
+	e.step.Reset(e.Speed)
+}
+
+// Picks a random open cell in the Enemy's area for wandering to
+func (e *Enemy) randomCell(grid *Grid) image.Point {
+	area := e.Area
+	if area.Empty() {
+		area = image.Rectangle{Max: grid.Size}
+	}
+	for {
+		p := area.Min.Add(image.Pt(e.rng.Intn(area.Dx()), e.rng.Intn(area.Dy())))
+		if !grid.Wall(p) {
+			return p
+		}
+	}
+}
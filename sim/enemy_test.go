@@ -0,0 +1,194 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/sinisterstuf/dynamo/timer"
+)
+
+// makes a Grid from rows of text, # for walls
+func gridFrom(rows ...string) *Grid {
+	g := NewGrid(image.Pt(len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, c := range row {
+			g.Walls[g.Index(image.Pt(x, y))] = c == '#'
+		}
+	}
+	return g
+}
+
+// a long straight corridor with a side passage at the far end
+var corridor = []string{
+	"###########",
+	"#.........#",
+	"#########.#",
+	"#########.#",
+	"###########",
+}
+
+// runs an enemy for a number of ticks
+func runEnemy(e *Enemy, clock *timer.Scheduler, grid *Grid, player image.Point, torchOn bool, ticks int) {
+	for range ticks {
+		e.Update(grid, player, torchOn)
+		clock.Update()
+	}
+}
+
+func TestEnemyChasesTorchLight(t *testing.T) {
+	grid := gridFrom(corridor...)
+	clock := timer.New()
+	e := NewEnemy(image.Pt(9, 1), 2, rand.NewSource(1), clock)
+	player := image.Pt(1, 1)
+
+	runEnemy(e, clock, grid, player, true, 1)
+	if !e.Chasing {
+		t.Fatal("not chasing a lit torch in plain sight")
+	}
+	runEnemy(e, clock, grid, player, true, 30)
+	if !e.Caught(player) {
+		t.Errorf("at %v after 30 ticks, want to have caught the player at %v", e.Coords, player)
+	}
+}
+
+func TestEnemyIgnoresDarkness(t *testing.T) {
+	grid := gridFrom(corridor...)
+	clock := timer.New()
+	e := NewEnemy(image.Pt(9, 1), 2, rand.NewSource(1), clock)
+	runEnemy(e, clock, grid, image.Pt(1, 1), false, 1)
+	if e.Chasing {
+		t.Error("chasing a player whose torch is off")
+	}
+}
+
+func TestEnemyGoesWherePlayerWasLastSeen(t *testing.T) {
+	grid := gridFrom(corridor...)
+	clock := timer.New()
+	e := NewEnemy(image.Pt(9, 1), 1, rand.NewSource(1), clock)
+	seen := image.Pt(3, 1)
+	runEnemy(e, clock, grid, seen, true, 1)
+	// The player turns the torch off and gets away round the corner
+	runEnemy(e, clock, grid, image.Pt(9, 3), false, 5)
+	if !e.Chasing || e.Path[len(e.Path)-1] != seen {
+		t.Errorf("chasing %v to %v, want to be going to %v", e.Chasing, e.Path, seen)
+	}
+}
+
+func TestEnemyCantSeeRoundCorners(t *testing.T) {
+	grid := gridFrom(corridor...)
+	clock := timer.New()
+	e := NewEnemy(image.Pt(1, 1), 1, rand.NewSource(1), clock)
+	runEnemy(e, clock, grid, image.Pt(9, 3), true, 1)
+	if e.Chasing {
+		t.Error("chasing a torch round a corner")
+	}
+}
+
+func TestEnemyWandersCorridors(t *testing.T) {
+	grid := gridFrom(
+		"#######",
+		"#.....#",
+		"#.###.#",
+		"#.....#",
+		"#######",
+	)
+	clock := timer.New()
+	e := NewEnemy(image.Pt(1, 1), 1, rand.NewSource(3), clock)
+	moved := false
+	for range 200 {
+		runEnemy(e, clock, grid, image.Pt(-1, -1), false, 1)
+		if grid.Wall(e.Coords) {
+			t.Fatalf("wandered into a wall at %v", e.Coords)
+		}
+		moved = moved || e.Coords != e.Start
+	}
+	if !moved {
+		t.Error("never moved")
+	}
+}
+
+func TestEnemyWandersInsideItsArea(t *testing.T) {
+	grid := gridFrom(
+		"#####",
+		"#...#",
+		"#...#",
+		"#####",
+		"#...#",
+		"#...#",
+		"#####",
+	)
+	clock := timer.New()
+	e := NewEnemy(image.Pt(1, 1), 1, rand.NewSource(3), clock)
+	e.Area = image.Rect(0, 0, 5, 4)
+	for range 200 {
+		runEnemy(e, clock, grid, image.Pt(-1, -1), false, 1)
+		if !e.Coords.In(e.Area) || grid.Wall(e.Coords) {
+			t.Fatalf("wandered to %v", e.Coords)
+		}
+	}
+}
+
+func TestEnemyStopsAtShiftedWall(t *testing.T) {
+	grid := gridFrom(corridor...)
+	clock := timer.New()
+	e := NewEnemy(image.Pt(9, 1), 1, rand.NewSource(1), clock)
+	runEnemy(e, clock, grid, image.Pt(1, 1), true, 1)
+	next := e.Path[0]
+	grid.Walls[grid.Index(next)] = true
+	runEnemy(e, clock, grid, image.Pt(1, 1), false, 5)
+	if grid.Wall(e.Coords) {
+		t.Errorf("walked into a wall at %v", e.Coords)
+	}
+}
+
+func TestEnemyReset(t *testing.T) {
+	grid := gridFrom(corridor...)
+	clock := timer.New()
+	start := image.Pt(9, 1)
+	e := NewEnemy(start, 1, rand.NewSource(1), clock)
+	runEnemy(e, clock, grid, image.Pt(1, 1), true, 5)
+	e.Reset()
+	if e.Coords != start || e.Chasing || len(e.Path) > 0 {
+		t.Errorf("after reset at %v chasing %v with path %v", e.Coords, e.Chasing, e.Path)
+	}
+}
+
+func TestSpawnEnemiesFarAway(t *testing.T) {
+	grid := gridFrom(corridor...)
+	from := image.Pt(1, 1)
+	d := Difficulty{Enemies: 5, EnemySpeed: 4}
+	enemies := SpawnEnemies(grid, from, []image.Rectangle{{Max: grid.Size}}, d, rand.NewSource(1), timer.New())
+	if len(enemies) != 5 {
+		t.Fatalf("%d enemies, want 5", len(enemies))
+	}
+	dist := grid.Distances(from)
+	for _, e := range enemies {
+		if dist[grid.Index(e.Coords)]*2 < 10 {
+			t.Errorf("spawned at %v, only %d steps from the player", e.Coords, dist[grid.Index(e.Coords)])
+		}
+		if e.Speed != d.EnemySpeed {
+			t.Errorf("speed %d, want %d", e.Speed, d.EnemySpeed)
+		}
+	}
+}
+
+func TestSpawnEnemiesOnEveryFloor(t *testing.T) {
+	grid := gridFrom(
+		"#####",
+		"#...#",
+		"#####",
+		"#...#",
+		"#####",
+		"#...#",
+		"#####",
+	)
+	floors := []image.Rectangle{image.Rect(0, 0, 5, 2), image.Rect(0, 2, 5, 4), image.Rect(0, 4, 5, 7)}
+	d := Difficulty{Enemies: 3, EnemySpeed: 4}
+	enemies := SpawnEnemies(grid, image.Pt(1, 1), floors, d, rand.NewSource(1), timer.New())
+	for i, e := range enemies {
+		if !e.Coords.In(floors[i]) || e.Area != floors[i] {
+			t.Errorf("enemy %d at %v in %v, want on floor %v", i, e.Coords, e.Area, floors[i])
+		}
+	}
+}
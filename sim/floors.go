@@ -0,0 +1,82 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+)
+
+// StackFloors piles maze floors on top of each other into one Grid
+// The floors all have to be the same size.  They go from the top floor at the
+// top of the grid to the bottom floor at the bottom, and as every floor has
+// walls all round it they can only be got between by stairs.
+func StackFloors(floors []*Grid) *Grid {
+	size := floors[0].Size
+	grid := NewGrid(image.Pt(size.X, size.Y*len(floors)))
+	for f, floor := range floors {
+		copy(grid.Walls[f*len(floor.Walls):], floor.Walls)
+	}
+	return grid
+}
+
+// PlaceStairs joins each floor to the one below it by one flight of stairs
+// Each floor is a perfect maze, and joining them up by a single link each
+// keeps the whole stack a perfect maze: there's still exactly one way
+// between any two cells, now going up and down stairs.
+func PlaceStairs(m *Maze, source rand.Source, start image.Point) {
+	rng := rand.New(source)
+	for f := 0; f+1 < m.Floors; f++ {
+		// Stairs go on cells, which are at odd coordinates in every floor
+		var spots []image.Point
+		for y := 1; y < m.FloorSize.Y; y += 2 {
+			for x := 1; x < m.FloorSize.X; x += 2 {
+				top := image.Pt(x, y).Add(m.FloorRect(f).Min)
+				bottom := image.Pt(x, y).Add(m.FloorRect(f + 1).Min)
+				if !top.Eq(start) && m.Open(top) && m.Open(bottom) && m.Object(top) == nil {
+					spots = append(spots, top)
+				}
+			}
+		}
+		if len(spots) == 0 {
+			continue
+		}
+		top := spots[rng.Intn(len(spots))]
+		bottom := top.Add(image.Pt(0, m.FloorSize.Y))
+		n := len(m.Objects)
+		m.Objects = append(m.Objects,
+			&Object{Kind: ObjectDown, Coords: top, Pair: n + 1},
+			&Object{Kind: ObjectUp, Coords: bottom, Pair: n},
+		)
+	}
+}
+
+// FloorRect is the part of the Grid that a floor of the maze takes up
+func (m *Maze) FloorRect(floor int) image.Rectangle {
+	corner := image.Pt(0, floor*m.FloorSize.Y)
+	return image.Rectangle{Min: corner, Max: corner.Add(m.FloorSize)}
+}
+
+// FloorRects are the parts of the Grid each floor takes up, top floor first
+func (m *Maze) FloorRects() []image.Rectangle {
+	rects := make([]image.Rectangle, m.Floors)
+	for f := range rects {
+		rects[f] = m.FloorRect(f)
+	}
+	return rects
+}
+
+// FloorOf is which floor of the maze a point is on
+// Points above or below the whole maze, like the exit, count as being on the
+// top or bottom floor.
+func (m *Maze) FloorOf(p image.Point) int {
+	return max(0, min(p.Y/m.FloorSize.Y, m.Floors-1))
+}
+
+// Link is where a point in the maze leads to other than its neighbours, e.g.
+// the other end of a flight of stairs
+func (m *Maze) Link(p image.Point) (image.Point, bool) {
+	o := m.Object(p)
+	if o == nil || (o.Kind != ObjectDown && o.Kind != ObjectUp) {
+		return image.Point{}, false
+	}
+	return m.Objects[o.Pair].Coords, true
+}
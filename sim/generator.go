@@ -0,0 +1,409 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+
+	"gitlab.com/zaba505/maze"
+)
+
+// Generator is an algorithm for making perfect mazes
+// A perfect maze has exactly one path between any two cells: every part of it
+// is reachable and there are no loops.  Each algorithm is biased towards a
+// different texture of corridors.
+type Generator interface {
+	// Name is a short identifier for picking the algorithm in options
+	Name() string
+	// Generate makes a maze of the given size in cells, not in pixels
+	Generate(source rand.Source, cells image.Point) *Grid
+}
+
+// Generators are all the maze algorithms the game knows about
+var Generators []Generator = []Generator{
+	Kruskal{},
+	Backtracker{},
+	Prim{},
+	Wilson{},
+	Eller{},
+	GrowingTree{},
+	BinaryTree{},
+}
+
+// GeneratorByName looks up a maze algorithm by its name
+// It returns nil if there is no algorithm with that name.
+func GeneratorByName(name string) Generator {
+	for _, gen := range Generators {
+		if gen.Name() == name {
+			return gen
+		}
+	}
+	return nil
+}
+
+// carver lays out a maze in a Grid while it's being generated
+// Cells are at odd coordinates with walls in between them, the same layout
+// the maze library uses, so a maze of w×h cells is 2w+1×2h+1 pixels.
+type carver struct {
+	*Grid
+	cells image.Point
+}
+
+// makes a carver for a grid that is nothing but walls
+func newCarver(cells image.Point) *carver {
+	c := &carver{
+		Grid:  NewGrid(image.Pt(cells.X*2+1, cells.Y*2+1)),
+		cells: cells,
+	}
+	for k := range c.Walls {
+		c.Walls[k] = true
+	}
+	return c
+}
+
+// returns the pixel position of a cell
+func (c *carver) pixel(cell image.Point) image.Point {
+	return image.Pt(cell.X*2+1, cell.Y*2+1)
+}
+
+// returns the cell at an index counting left to right, top to bottom
+func (c *carver) cell(i int) image.Point {
+	return image.Pt(i%c.cells.X, i/c.cells.X)
+}
+
+// returns how many cells there are in the maze
+func (c *carver) count() int {
+	return c.cells.X * c.cells.Y
+}
+
+// reports whether a cell has been carved out yet
+func (c *carver) carved(cell image.Point) bool {
+	return !c.Wall(c.pixel(cell))
+}
+
+// opens up a cell without joining it to anything
+func (c *carver) carve(cell image.Point) {
+	c.Walls[c.Index(c.pixel(cell))] = false
+}
+
+// opens up two neighbouring cells and the wall between them
+func (c *carver) join(a, b image.Point) {
+	c.carve(a)
+	c.carve(b)
+	c.Walls[c.Index(c.pixel(a).Add(c.pixel(b)).Div(2))] = false
+}
+
+// returns the cells next to a cell, in a random order
+func (c *carver) neighbours(r *rand.Rand, cell image.Point) []image.Point {
+	var ns []image.Point
+	for _, d := range directions {
+		n := cell.Add(d)
+		if n.In(image.Rectangle{Max: c.cells}) {
+			ns = append(ns, n)
+		}
+	}
+	r.Shuffle(len(ns), func(i, j int) { ns[i], ns[j] = ns[j], ns[i] })
+	return ns
+}
+
+// directions are the four ways you can go from any cell
+var directions []image.Point = []image.Point{
+	{0, -1}, {1, 0}, {0, 1}, {-1, 0},
+}
+
+// Kruskal joins random walls between unconnected areas, giving lots of short
+// dead ends.  This is the original algorithm from the maze library.
+type Kruskal struct{}
+
+// Name returns "kruskal"
+func (Kruskal) Name() string { return "kruskal" }
+
+// Generate makes a maze with the maze library's Kruskal generator
+func (Kruskal) Generate(source rand.Source, cells image.Point) *Grid {
+	gray := maze.Gray(maze.WithKruskal(source).Generate(cells.X, cells.Y))
+	g := NewGrid(gray.Rect.Size())
+	for k, v := range gray.Pix {
+		g.Walls[k] = v != 255
+	}
+	return g
+}
+
+// Backtracker is a randomised depth-first search, giving long winding
+// corridors with few dead ends
+type Backtracker struct{}
+
+// Name returns "backtracker"
+func (Backtracker) Name() string { return "backtracker" }
+
+// Generate makes a maze by walking randomly and backtracking at dead ends
+func (Backtracker) Generate(source rand.Source, cells image.Point) *Grid {
+	r := rand.New(source)
+	c := newCarver(cells)
+	start := c.cell(r.Intn(c.count()))
+	c.carve(start)
+	stack := []image.Point{start}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, n := range c.neighbours(r, cur) {
+			if !c.carved(n) {
+				c.join(cur, n)
+				stack = append(stack, cur, n)
+				break
+			}
+		}
+	}
+	return c.Grid
+}
+
+// Prim grows the maze outwards from a single cell in random directions,
+// giving many short branches radiating from the start
+type Prim struct{}
+
+// Name returns "prim"
+func (Prim) Name() string { return "prim" }
+
+// Generate makes a maze by adding random cells from its frontier
+func (Prim) Generate(source rand.Source, cells image.Point) *Grid {
+	r := rand.New(source)
+	c := newCarver(cells)
+	inFrontier := make(map[image.Point]bool)
+	var frontier []image.Point
+	add := func(cell image.Point) {
+		c.carve(cell)
+		for _, n := range c.neighbours(r, cell) {
+			if !c.carved(n) && !inFrontier[n] {
+				inFrontier[n] = true
+				frontier = append(frontier, n)
+			}
+		}
+	}
+	add(c.cell(r.Intn(c.count())))
+	for len(frontier) > 0 {
+		i := r.Intn(len(frontier))
+		cell := frontier[i]
+		frontier[i] = frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+		for _, n := range c.neighbours(r, cell) {
+			if c.carved(n) {
+				c.join(cell, n)
+				break
+			}
+		}
+		add(cell)
+	}
+	return c.Grid
+}
+
+// Wilson uses loop-erased random walks, giving a uniformly random maze
+// without any bias in its texture
+type Wilson struct{}
+
+// Name returns "wilson"
+func (Wilson) Name() string { return "wilson" }
+
+// Generate makes a maze by walking randomly until reaching the maze so far
+func (Wilson) Generate(source rand.Source, cells image.Point) *Grid {
+	r := rand.New(source)
+	c := newCarver(cells)
+	c.carve(c.cell(r.Intn(c.count())))
+	exits := make(map[image.Point]image.Point)
+	for i := 0; i < c.count(); i++ {
+		start := c.cell(i)
+		// Walk until hitting the maze, overwriting exits erases loops
+		end := start
+		for !c.carved(end) {
+			ns := c.neighbours(r, end)
+			exits[end] = ns[0]
+			end = ns[0]
+		}
+		for cur := start; cur != end; cur = exits[cur] {
+			c.join(cur, exits[cur])
+		}
+	}
+	return c.Grid
+}
+
+// Eller builds the maze one row at a time, giving long horizontal runs
+type Eller struct{}
+
+// Name returns "eller"
+func (Eller) Name() string { return "eller" }
+
+// Generate makes a maze row by row, tracking which cells are connected
+func (Eller) Generate(source rand.Source, cells image.Point) *Grid {
+	r := rand.New(source)
+	c := newCarver(cells)
+	sets := make([]int, cells.X)
+	next := 0
+	for x := range sets {
+		next++
+		sets[x] = next
+	}
+	merge := func(from, to int) {
+		for x, s := range sets {
+			if s == from {
+				sets[x] = to
+			}
+		}
+	}
+	for y := 0; y < cells.Y; y++ {
+		last := y == cells.Y-1
+		// Randomly join neighbours in different sets, all of them on the last row
+		for x := 0; x < cells.X-1; x++ {
+			c.carve(image.Pt(x, y))
+			if sets[x] != sets[x+1] && (last || r.Intn(2) == 0) {
+				c.join(image.Pt(x, y), image.Pt(x+1, y))
+				merge(sets[x+1], sets[x])
+			}
+		}
+		c.carve(image.Pt(cells.X-1, y))
+		if last {
+			break
+		}
+		// Every set needs at least one way down to the next row
+		below := make([]int, cells.X)
+		members := make(map[int][]int)
+		for x, s := range sets {
+			members[s] = append(members[s], x)
+		}
+		for x := range sets {
+			xs := members[sets[x]]
+			if xs == nil {
+				continue
+			}
+			delete(members, sets[x])
+			r.Shuffle(len(xs), func(i, j int) { xs[i], xs[j] = xs[j], xs[i] })
+			for k, mx := range xs {
+				if k == 0 || r.Intn(3) == 0 {
+					c.join(image.Pt(mx, y), image.Pt(mx, y+1))
+					below[mx] = sets[mx]
+				}
+			}
+		}
+		for x := range below {
+			if below[x] == 0 {
+				next++
+				below[x] = next
+			}
+		}
+		sets = below
+	}
+	return c.Grid
+}
+
+// GrowingTree grows the maze from a list of active cells, mixing the newest
+// and random ones, giving a texture between Backtracker and Prim
+type GrowingTree struct{}
+
+// Name returns "growingtree"
+func (GrowingTree) Name() string { return "growingtree" }
+
+// Generate makes a maze by growing it from a list of active cells
+func (GrowingTree) Generate(source rand.Source, cells image.Point) *Grid {
+	r := rand.New(source)
+	c := newCarver(cells)
+	start := c.cell(r.Intn(c.count()))
+	c.carve(start)
+	active := []image.Point{start}
+	for len(active) > 0 {
+		i := len(active) - 1
+		if r.Intn(2) == 0 {
+			i = r.Intn(len(active))
+		}
+		cur := active[i]
+		grown := false
+		for _, n := range c.neighbours(r, cur) {
+			if !c.carved(n) {
+				c.join(cur, n)
+				active = append(active, n)
+				grown = true
+				break
+			}
+		}
+		if !grown {
+			active = append(active[:i], active[i+1:]...)
+		}
+	}
+	return c.Grid
+}
+
+// BinaryTree joins every cell either up or left, giving a strong diagonal
+// bias with long open corridors along the top and left edges
+type BinaryTree struct{}
+
+// Name returns "binarytree"
+func (BinaryTree) Name() string { return "binarytree" }
+
+// Generate makes a maze by randomly joining each cell to one of two neighbours
+func (BinaryTree) Generate(source rand.Source, cells image.Point) *Grid {
+	r := rand.New(source)
+	c := newCarver(cells)
+	for i := 0; i < c.count(); i++ {
+		cell := c.cell(i)
+		c.carve(cell)
+		up, left := cell.Add(image.Pt(0, -1)), cell.Add(image.Pt(-1, 0))
+		switch {
+		case cell.X == 0 && cell.Y == 0:
+		case cell.X == 0:
+			c.join(cell, up)
+		case cell.Y == 0:
+			c.join(cell, left)
+		case r.Intn(2) == 0:
+			c.join(cell, up)
+		default:
+			c.join(cell, left)
+		}
+	}
+	return c.Grid
+}
+
+// Braid knocks through walls at a percentage of a perfect maze's dead ends
+// This makes loops in the maze so that there is more than one way to get
+// around.  Where possible dead ends are joined to other dead ends, which gets
+// rid of two at once.
+func Braid(grid *Grid, source rand.Source, percent int) {
+	r := rand.New(source)
+	c := &carver{Grid: grid, cells: grid.Size.Sub(image.Pt(1, 1)).Div(2)}
+	deadEnd := func(cell image.Point) bool {
+		exits := 0
+		for _, d := range directions {
+			if !c.Wall(c.pixel(cell).Add(d)) {
+				exits++
+			}
+		}
+		return exits == 1
+	}
+
+	var deadEnds []image.Point
+	for i := 0; i < c.count(); i++ {
+		if cell := c.cell(i); c.carved(cell) && deadEnd(cell) {
+			deadEnds = append(deadEnds, cell)
+		}
+	}
+	r.Shuffle(len(deadEnds), func(i, j int) {
+		deadEnds[i], deadEnds[j] = deadEnds[j], deadEnds[i]
+	})
+
+	for _, cell := range deadEnds[:len(deadEnds)*percent/100] {
+		if !deadEnd(cell) { // already joined to an earlier dead end
+			continue
+		}
+		var walled []image.Point
+		for _, n := range c.neighbours(r, cell) {
+			if c.Wall(c.pixel(cell).Add(c.pixel(n)).Div(2)) {
+				walled = append(walled, n)
+			}
+		}
+		if len(walled) == 0 {
+			continue
+		}
+		best := walled[0]
+		for _, n := range walled {
+			if deadEnd(n) {
+				best = n
+				break
+			}
+		}
+		c.join(cell, best)
+	}
+}
@@ -0,0 +1,77 @@
+package sim
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"testing"
+)
+
+func TestGeneratorsMakePerfectMazes(t *testing.T) {
+	sizes := []image.Point{
+		{1, 1}, {1, 7}, {7, 1}, {2, 2}, {7, 3}, {13, 7}, {20, 11}, {41, 23},
+	}
+	for _, gen := range Generators {
+		for _, size := range sizes {
+			for seed := int64(0); seed < 5; seed++ {
+				name := fmt.Sprintf("%s/%dx%d/%d", gen.Name(), size.X, size.Y, seed)
+				t.Run(name, func(t *testing.T) {
+					g := gen.Generate(rand.NewSource(seed), size)
+					want := size.Mul(2).Add(image.Pt(1, 1))
+					if g.Size != want {
+						t.Fatalf("grid is %v, want %v", g.Size, want)
+					}
+					checkPerfect(t, g)
+				})
+			}
+		}
+	}
+}
+
+// checks that every open cell of a grid is connected and there are no loops,
+// which in a graph of open cells joined to their neighbours means there is
+// one edge fewer than there are cells
+func checkPerfect(t *testing.T, g *Grid) {
+	t.Helper()
+	open, edges := 0, 0
+	var first image.Point
+	for k, wall := range g.Walls {
+		if wall {
+			continue
+		}
+		p := image.Pt(k%g.Size.X, k/g.Size.X)
+		if open == 0 {
+			first = p
+		}
+		open++
+		// Only count right and down so each edge is counted once
+		for _, d := range []image.Point{{1, 0}, {0, 1}} {
+			if n := p.Add(d); g.In(n) && !g.Wall(n) {
+				edges++
+			}
+		}
+	}
+	if open == 0 {
+		t.Fatal("no open cells")
+	}
+
+	// Flood fill from the first open cell to see how many can be reached
+	seen := map[image.Point]bool{first: true}
+	queue := []image.Point{first}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			if n := p.Add(d); g.In(n) && !g.Wall(n) && !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	if len(seen) != open {
+		t.Errorf("%d of %d open cells reachable", len(seen), open)
+	}
+	if edges != open-1 {
+		t.Errorf("%d edges between %d open cells, want %d", edges, open, open-1)
+	}
+}
@@ -0,0 +1,191 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"sort"
+)
+
+// GhostMagic is at the start of every ghost file
+const GhostMagic string = "DYNG"
+
+// GhostVersion is the version of the ghost file format that gets written
+// It's followed by the RulesVersion the maze was generated under.
+const GhostVersion byte = 1
+
+// Ghost is the route the player took through one maze and when they took it
+// A maze is identified by the run's seed, how deep into the game it is, the
+// algorithm that generated it and whether it's in endless mode or has shifting
+// walls, so the same maze can be raced again.
+type Ghost struct {
+	Seed      int64
+	Depth     int
+	Endless   bool
+	Shifting  bool
+	Generator string
+	Ticks     int         // How long it took to reach the exit, 0 if it didn't
+	Steps     []GhostStep // Every cell the player moved into, in order
+}
+
+// GhostStep is the player moving into a cell at a tick of their attempt
+type GhostStep struct {
+	Tick   int         `json:"tick"`
+	Coords image.Point `json:"coords"`
+}
+
+// NewGhost starts an empty route through the current maze of a run
+func NewGhost(s *Sim) *Ghost {
+	return &Ghost{
+		Seed:      s.Seed,
+		Depth:     s.Depth,
+		Endless:   s.Endless,
+		Shifting:  s.Shifting,
+		Generator: s.MazeGenerator().Name(),
+	}
+}
+
+// Key is a name for the maze the ghost ran through, for storing it under
+func (g *Ghost) Key() string {
+	key := fmt.Sprintf("%d-%d-%s", g.Seed, g.Depth, g.Generator)
+	if g.Endless {
+		key += "-endless"
+	}
+	if g.Shifting {
+		key += "-shifting"
+	}
+	return key
+}
+
+// Add records the player being at a cell, if they moved since the last one
+func (g *Ghost) Add(tick int, coords image.Point) {
+	if n := len(g.Steps); n > 0 && g.Steps[n-1].Coords.Eq(coords) {
+		return
+	}
+	g.Steps = append(g.Steps, GhostStep{tick, coords})
+}
+
+// Finish marks the route as having reached the exit after a number of ticks
+func (g *Ghost) Finish(ticks int) {
+	g.Ticks = ticks
+}
+
+// Faster reports whether this route reached the exit sooner than another one
+func (g *Ghost) Faster(other *Ghost) bool {
+	return g.Ticks > 0 && (other == nil || other.Ticks == 0 || g.Ticks < other.Ticks)
+}
+
+// At returns where the ghost was at a tick of its attempt at the maze
+// Players move a whole cell at a time so the ghost stays in each cell until
+// the tick it moved on to the next. It's false after the ghost has left
+// through the exit.
+func (g *Ghost) At(tick int) (image.Point, bool) {
+	if len(g.Steps) == 0 || (g.Ticks > 0 && tick > g.Ticks) {
+		return image.Point{}, false
+	}
+	i := sort.Search(len(g.Steps), func(i int) bool { return g.Steps[i].Tick > tick })
+	return g.Steps[max(i-1, 0)].Coords, true
+}
+
+// WriteTo writes the ghost in its compact file format
+// Each step is stored as the ticks and the distance since the one before.
+func (g *Ghost) WriteTo(w io.Writer) (int64, error) {
+	buf := []byte(GhostMagic)
+	buf = append(buf, GhostVersion)
+	buf = binary.AppendUvarint(buf, uint64(RulesVersion))
+	buf = binary.AppendVarint(buf, g.Seed)
+	buf = binary.AppendUvarint(buf, uint64(g.Depth))
+	var flags byte
+	if g.Endless {
+		flags |= 1
+	}
+	if g.Shifting {
+		flags |= 2
+	}
+	buf = append(buf, flags)
+	buf = binary.AppendUvarint(buf, uint64(len(g.Generator)))
+	buf = append(buf, g.Generator...)
+	buf = binary.AppendUvarint(buf, uint64(g.Ticks))
+	buf = binary.AppendUvarint(buf, uint64(len(g.Steps)))
+	var prev GhostStep
+	for _, s := range g.Steps {
+		buf = binary.AppendUvarint(buf, uint64(s.Tick-prev.Tick))
+		buf = binary.AppendVarint(buf, int64(s.Coords.X-prev.Coords.X))
+		buf = binary.AppendVarint(buf, int64(s.Coords.Y-prev.Coords.Y))
+		prev = s
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadGhost reads a ghost written by WriteTo
+func ReadGhost(r io.Reader) (*Ghost, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(GhostMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("reading ghost header: %w", err)
+	}
+	if string(header[:len(GhostMagic)]) != GhostMagic {
+		return nil, errors.New("not a ghost file")
+	}
+	if v := header[len(GhostMagic)]; v != GhostVersion {
+		return nil, fmt.Errorf("unsupported ghost version %d", v)
+	}
+
+	// Every number is a varint, so errors can be checked once at the end
+	var err error
+	uvarint := func() int {
+		if err != nil {
+			return 0
+		}
+		var v uint64
+		v, err = binary.ReadUvarint(br)
+		return int(v)
+	}
+	varint := func() int {
+		if err != nil {
+			return 0
+		}
+		var v int64
+		v, err = binary.ReadVarint(br)
+		return int(v)
+	}
+
+	if rules := uvarint(); err == nil && rules != RulesVersion {
+		return nil, fmt.Errorf("ghost is for version %d of the rules, not %d", rules, RulesVersion)
+	}
+	g := &Ghost{}
+	g.Seed = int64(varint())
+	g.Depth = uvarint()
+	if err == nil {
+		var flags byte
+		flags, err = br.ReadByte()
+		g.Endless = flags&1 != 0
+		g.Shifting = flags&2 != 0
+	}
+	nameLen := uvarint()
+	if err == nil && (nameLen < 0 || nameLen > 64) {
+		return nil, fmt.Errorf("ghost generator name is %d long", nameLen)
+	}
+	name := make([]byte, nameLen)
+	if err == nil {
+		_, err = io.ReadFull(br, name)
+	}
+	g.Generator = string(name)
+	g.Ticks = uvarint()
+	n := uvarint()
+	var prev GhostStep
+	for i := 0; i < n && err == nil; i++ {
+		prev.Tick += uvarint()
+		prev.Coords.X += varint()
+		prev.Coords.Y += varint()
+		g.Steps = append(g.Steps, prev)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading ghost: %w", err)
+	}
+	return g, nil
+}
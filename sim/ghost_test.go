@@ -0,0 +1,62 @@
+package sim
+
+import (
+	"bytes"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGhostRoundTrip(t *testing.T) {
+	g := NewGhost(NewEndless(9, nil, true))
+	g.Add(0, image.Pt(1, 1))
+	g.Add(15, image.Pt(2, 1))
+	g.Add(17, image.Pt(2, 1))
+	g.Add(30, image.Pt(1, 1))
+	g.Finish(31)
+	var buf bytes.Buffer
+	if _, err := g.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadGhost(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, g) {
+		t.Errorf("read back %+v, want %+v", got, g)
+	}
+}
+
+func TestGhostAt(t *testing.T) {
+	g := &Ghost{Steps: []GhostStep{{0, image.Pt(1, 1)}, {15, image.Pt(2, 1)}, {17, image.Pt(3, 1)}}}
+	g.Finish(20)
+	for tick, want := range map[int]image.Point{0: {1, 1}, 14: {1, 1}, 15: {2, 1}, 16: {2, 1}, 20: {3, 1}} {
+		if got, ok := g.At(tick); !ok || got != want {
+			t.Errorf("at tick %d at %v, want %v", tick, got, want)
+		}
+	}
+	if _, ok := g.At(21); ok {
+		t.Error("still there after leaving through the exit")
+	}
+}
+
+func TestReadGhostRejectsOtherRules(t *testing.T) {
+	var buf bytes.Buffer
+	NewGhost(New(1, LevelBeginner, nil, false)).WriteTo(&buf)
+	data := buf.Bytes()
+	data[len(GhostMagic)+1]++ // The rules version comes straight after the file version
+	if _, err := ReadGhost(bytes.NewReader(data)); err == nil {
+		t.Error("read a ghost for other rules without an error")
+	}
+}
+
+func TestReadGhostRejectsLongNames(t *testing.T) {
+	g := NewGhost(New(1, LevelBeginner, nil, false))
+	g.Generator = strings.Repeat("Maze", 20)
+	var buf bytes.Buffer
+	g.WriteTo(&buf)
+	if _, err := ReadGhost(&buf); err == nil {
+		t.Error("read a ghost with a name too long without an error")
+	}
+}
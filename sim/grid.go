@@ -0,0 +1,148 @@
+package sim
+
+import (
+	"image"
+)
+
+// Grid is the logical layout of a maze with one cell per pixel on screen
+// It is kept alongside the maze image so that game logic like collision and
+// line-of-sight doesn't need to read pixels back from the GPU.
+type Grid struct {
+	Size  image.Point
+	Walls []bool
+}
+
+// NewGrid makes an empty Grid of the given size with no walls in it
+func NewGrid(size image.Point) *Grid {
+	return &Grid{
+		Size:  size,
+		Walls: make([]bool, size.X*size.Y),
+	}
+}
+
+// In reports whether a point lies inside the grid
+func (g *Grid) In(p image.Point) bool {
+	return p.In(image.Rectangle{Max: g.Size})
+}
+
+// Index returns the offset of a point in the grid's cell slices
+func (g *Grid) Index(p image.Point) int {
+	return p.Y*g.Size.X + p.X
+}
+
+// Wall reports whether there is a wall at a point
+// Anything outside the grid is open space, which is how the exit works.
+func (g *Grid) Wall(p image.Point) bool {
+	return g.In(p) && g.Walls[g.Index(p)]
+}
+
+// Light works out which cells a torch at a point can see within a radius
+// The result is indexed the same as Walls.  Light travels in straight lines
+// and stops at the first wall it hits, so walls are lit but not what's behind
+// them.
+func (g *Grid) Light(from image.Point, radius int) []bool {
+	lit := make([]bool, len(g.Walls))
+	area := image.Rect(
+		from.X-radius, from.Y-radius,
+		from.X+radius+1, from.Y+radius+1,
+	).Intersect(image.Rectangle{Max: g.Size})
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			to := image.Pt(x, y)
+			d := to.Sub(from)
+			if d.X*d.X+d.Y*d.Y > radius*radius {
+				continue
+			}
+			lit[g.Index(to)] = g.Visible(from, to)
+		}
+	}
+	return lit
+}
+
+// Visible reports whether there is a clear line of sight between two points
+// The end points themselves may be walls, only the cells in between count.
+func (g *Grid) Visible(from, to image.Point) bool {
+	nx, ny := abs(to.X-from.X), abs(to.Y-from.Y)
+	sx, sy := sign(to.X-from.X), sign(to.Y-from.Y)
+	p := from
+	// Step one axis at a time so light can't slip diagonally between walls
+	for ix, iy := 0, 0; ix < nx || iy < ny; {
+		if (1+2*ix)*ny < (1+2*iy)*nx {
+			p.X += sx
+			ix++
+		} else {
+			p.Y += sy
+			iy++
+		}
+		if p != to && g.Wall(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Distances counts the steps from a point to every cell reachable from it
+// The result is indexed the same as Walls, with -1 for unreachable cells.
+func (g *Grid) Distances(from image.Point) []int {
+	dist := make([]int, len(g.Walls))
+	for k := range dist {
+		dist[k] = -1
+	}
+	if !g.In(from) || g.Wall(from) {
+		return dist
+	}
+	dist[g.Index(from)] = 0
+	queue := []image.Point{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range directions {
+			n := p.Add(d)
+			if g.In(n) && !g.Wall(n) && dist[g.Index(n)] < 0 {
+				dist[g.Index(n)] = dist[g.Index(p)] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return dist
+}
+
+// Path finds a shortest route between two points inside the grid
+// The route doesn't include the starting point but does include the
+// destination.  It is empty if there is no way to get there.
+func (g *Grid) Path(from, to image.Point) []image.Point {
+	dist := g.Distances(to)
+	if !g.In(from) || dist[g.Index(from)] < 0 {
+		return nil
+	}
+	// Walk downhill from the start towards the destination
+	var path []image.Point
+	for p := from; p != to; {
+		for _, d := range directions {
+			n := p.Add(d)
+			if g.In(n) && dist[g.Index(n)] == dist[g.Index(p)]-1 {
+				p = n
+				break
+			}
+		}
+		path = append(path, p)
+	}
+	return path
+}
@@ -0,0 +1,33 @@
+package sim
+
+// Button is one of the controls of the game
+type Button int
+
+// Buttons are all the controls the simulation knows about
+const (
+	ButtonUp Button = iota
+	ButtonDown
+	ButtonLeft
+	ButtonRight
+	ButtonTorch
+	ButtonCrankA
+	ButtonCrankB
+	ButtonRestart // Start the current maze over, e.g. from the pause menu
+	ButtonNext    // Go on to the next maze from the results screen
+	ButtonHint    // Show the next few steps of the way out
+)
+
+// Input is which buttons are held down during one tick, one bit per Button
+// Whether a button was only just pressed is worked out by comparing with the
+// Input of the tick before, so a couple of bytes is all a tick needs.
+type Input uint16
+
+// Held reports whether a button is held down
+func (in Input) Held(b Button) bool {
+	return in&(1<<b) != 0
+}
+
+// With returns the Input with a button held down as well
+func (in Input) With(b Button) Input {
+	return in | 1<<b
+}
@@ -0,0 +1,227 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+	"slices"
+)
+
+// Maze contains all information about mazes
+// Not just the generated maze layout but also any other meta-data that can be
+// used for interacting with the maze.
+type Maze struct {
+	Grid      *Grid       // Logical layout for collision, sight & solving, all floors stacked
+	Floors    int         // How many floors the maze has, the exit is on the bottom one
+	FloorSize image.Point // Size of each floor in the Grid
+	Exit      image.Point // The exit location, for end-game logic
+	Lit       []bool      // Cells currently lit by the torch, same as Grid
+	Explored  []bool      // Cells the player has walked through or lit
+	Objects   []*Object   // Keys, doors, switches and gates in the maze
+	Shifters  []*Shifter  // Pieces of wall that can open and shut
+}
+
+// NewMaze generates a new maze based on difficulty and random source
+// The maze layout comes from the given Generator algorithm.
+// A maze with more than one floor has a maze on each floor, joined up by
+// stairs.  Doors and gates are put across the way out from the start, with the
+// keys and switches that open them, then portals go in some of the dead ends
+// and some walls are picked out that can shift.
+func NewMaze(gen Generator, source rand.Source, start image.Point, d Difficulty) *Maze {
+	floors := make([]*Grid, max(1, d.Floors))
+	for f := range floors {
+		floors[f] = gen.Generate(source, d.Size)
+		Braid(floors[f], source, d.Braid)
+	}
+	grid := StackFloors(floors)
+
+	// Find an exit at the bottom right
+	var exit image.Point
+	for i := grid.Size.X - 1; i > 0; i-- {
+		if !grid.Wall(image.Pt(i, grid.Size.Y-2)) {
+			exit = image.Pt(i, grid.Size.Y)
+			grid.Walls[grid.Index(exit.Sub(image.Pt(0, 1)))] = false
+			break
+		}
+	}
+
+	m := &Maze{
+		Grid:      grid,
+		Floors:    len(floors),
+		FloorSize: floors[0].Size,
+		Exit:      exit,
+		Lit:       make([]bool, len(grid.Walls)),
+		Explored:  make([]bool, len(grid.Walls)),
+	}
+	PlaceStairs(m, source, start)
+	PlaceObjects(m, source, start, d.Doors, d.Gates)
+	PlacePortals(m, source, start, d.Portals)
+	PlaceShifters(m, source, d.Shifters)
+	return m
+}
+
+// Open reports whether the player can stand at a point in the maze
+// Walls are never open, and nor are doors and gates until they're opened.
+func (m *Maze) Open(p image.Point) bool {
+	return !m.Grid.Wall(p) && !m.Closed(p)
+}
+
+// Illuminate lights up the maze around a point and remembers what was seen
+// A radius of 0 means the torch is off and nothing is lit.
+func (m *Maze) Illuminate(from image.Point, radius int) {
+	if radius == 0 {
+		clear(m.Lit)
+		return
+	}
+	m.Lit = m.Grid.Light(from, radius)
+	for k, l := range m.Lit {
+		m.Explored[k] = m.Explored[k] || l
+	}
+}
+
+// Visit remembers that the player has walked through a point
+func (m *Maze) Visit(p image.Point) {
+	if m.Grid.In(p) {
+		m.Explored[m.Grid.Index(p)] = true
+	}
+}
+
+// Solution is the shortest way from a cell out through the exit
+// It doesn't include the starting cell and ends with the exit.  If the way out
+// is locked it's the shortest way to a key or switch that opens something up
+// instead, ending there, and it's nil if there's nothing left to reach.
+func (m *Maze) Solution(from image.Point) []image.Point {
+	above := m.Exit.Sub(image.Pt(0, 1))
+	path := m.route(from, func(p image.Point) bool { return p.Eq(above) })
+	if path != nil {
+		return append(path, m.Exit)
+	}
+	return m.route(from, func(p image.Point) bool {
+		o := m.Object(p)
+		return o != nil && m.useful(o)
+	})
+}
+
+// Par is how many steps the shortest way out of the maze takes from a cell,
+// going to get whatever keys and switches it takes on the way
+func (m *Maze) Par(from image.Point) int {
+	done := make([]bool, len(m.Objects))
+	for k, o := range m.Objects {
+		done[k] = o.Done
+	}
+	defer func() {
+		for k, o := range m.Objects {
+			o.Done = done[k]
+		}
+	}()
+
+	steps := 0
+	for range len(m.Objects) + 1 {
+		path := m.Solution(from)
+		steps += len(path)
+		if len(path) == 0 || path[len(path)-1].Eq(m.Exit) {
+			break
+		}
+		for _, p := range path {
+			m.Unlock(p)
+			m.Touch(p)
+		}
+		from = path[len(path)-1]
+	}
+	return steps
+}
+
+// Passable reports whether the player could get through a point, counting
+// doors they have the key for as open
+func (m *Maze) Passable(p image.Point) bool {
+	if m.Open(p) {
+		return true
+	}
+	o := m.Object(p)
+	return o != nil && m.unlockable(o)
+}
+
+// Neighbours are the cells the player can get to in one move from a cell,
+// including by taking the stairs or going through a portal
+func (m *Maze) Neighbours(p image.Point) []image.Point {
+	var ns []image.Point
+	for _, d := range directions {
+		if n := p.Add(d); m.Grid.In(n) && m.Passable(n) {
+			ns = append(ns, m.Arrive(n))
+		}
+	}
+	if n, ok := m.Link(p); ok && m.Passable(n) {
+		ns = append(ns, n)
+	}
+	return ns
+}
+
+// finds a shortest route from a cell to the nearest cell that is a goal
+// The route doesn't include the starting cell but does include the goal, and
+// it's empty but not nil if the start is a goal already.  It's nil if no goal
+// can be reached.
+func (m *Maze) route(from image.Point, goal func(image.Point) bool) []image.Point {
+	if !m.Grid.In(from) {
+		return nil
+	}
+	if goal(from) {
+		return []image.Point{}
+	}
+	came := make([]int, len(m.Grid.Walls))
+	for k := range came {
+		came[k] = -1
+	}
+	start := m.Grid.Index(from)
+	came[start] = start
+	queue := []image.Point{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, n := range m.Neighbours(p) {
+			k := m.Grid.Index(n)
+			if came[k] >= 0 {
+				continue
+			}
+			came[k] = m.Grid.Index(p)
+			if goal(n) {
+				// Follow the trail back to the start
+				var path []image.Point
+				for ; k != start; k = came[k] {
+					path = append(path, image.Pt(k%m.Grid.Size.X, k/m.Grid.Size.X))
+				}
+				slices.Reverse(path)
+				return path
+			}
+			queue = append(queue, n)
+		}
+	}
+	return nil
+}
+
+// lists the cells next to a cell and at the other end of any stairs from it
+func (m *Maze) links(p image.Point) []image.Point {
+	ns := make([]image.Point, 0, len(directions)+1)
+	for _, d := range directions {
+		ns = append(ns, p.Add(d))
+	}
+	if n, ok := m.Link(p); ok {
+		ns = append(ns, n)
+	}
+	return ns
+}
+
+// lists every cell reachable from a cell through corridor cells that are
+// allowed, whatever objects are in them apart from stairs
+func (m *Maze) reachable(from image.Point, allowed func(image.Point) bool) []image.Point {
+	seen := make([]bool, len(m.Grid.Walls))
+	seen[m.Grid.Index(from)] = true
+	cells := []image.Point{from}
+	for i := 0; i < len(cells); i++ {
+		for _, n := range m.links(cells[i]) {
+			if m.Grid.In(n) && !m.Grid.Wall(n) && !seen[m.Grid.Index(n)] && allowed(n) {
+				seen[m.Grid.Index(n)] = true
+				cells = append(cells, n)
+			}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,214 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+)
+
+// ObjectKind is what sort of thing an Object in the maze is
+type ObjectKind int
+
+// Objects come in pairs: a key opens a door, a switch opens a gate, stairs
+// down lead to stairs up at the same spot on the floor below, and portals go
+// to each other
+const (
+	ObjectKey    ObjectKind = iota // Picked up by walking over it, opens its door
+	ObjectDoor                     // Locked until the player walks into it with its key
+	ObjectSwitch                   // Pressed by walking over it, opens its gate
+	ObjectGate                     // Shut until its switch is pressed
+	ObjectDown                     // Stairs down to the floor below
+	ObjectUp                       // Stairs up to the floor above
+	ObjectPortal                   // Sends the player to the other portal of its pair
+)
+
+// Object is something in the maze on top of the walls and corridors
+type Object struct {
+	Kind   ObjectKind  `json:"kind"`
+	Coords image.Point `json:"coords"`
+	Pair   int         `json:"pair"` // Index in Maze.Objects of the object it goes with
+	Done   bool        `json:"done"` // Key taken, door unlocked, switch pressed or gate open
+}
+
+// Lock reports whether the object is in the way until it's opened
+func (o *Object) Lock() bool {
+	return o.Kind == ObjectDoor || o.Kind == ObjectGate
+}
+
+// pairs with the kind of object that goes with one of this kind
+func (k ObjectKind) pairs() ObjectKind {
+	switch k {
+	case ObjectKey:
+		return ObjectDoor
+	case ObjectDoor:
+		return ObjectKey
+	case ObjectSwitch:
+		return ObjectGate
+	case ObjectGate:
+		return ObjectSwitch
+	case ObjectDown:
+		return ObjectUp
+	case ObjectUp:
+		return ObjectDown
+	}
+	return k
+}
+
+// Object returns the object at a point in the maze, or nil if there isn't one
+func (m *Maze) Object(p image.Point) *Object {
+	for _, o := range m.Objects {
+		if o.Coords.Eq(p) {
+			return o
+		}
+	}
+	return nil
+}
+
+// Closed reports whether a point is blocked by a door or gate that's shut
+func (m *Maze) Closed(p image.Point) bool {
+	o := m.Object(p)
+	return o != nil && o.Lock() && !o.Done
+}
+
+// Unlock opens a door at a point if the player is carrying its key
+func (m *Maze) Unlock(p image.Point) {
+	if o := m.Object(p); o != nil && m.unlockable(o) {
+		o.Done = true
+	}
+}
+
+// Touch picks up a key or presses a switch at a point the player walked onto
+func (m *Maze) Touch(p image.Point) {
+	o := m.Object(p)
+	if o == nil || o.Done {
+		return
+	}
+	switch o.Kind {
+	case ObjectKey:
+		o.Done = true
+	case ObjectSwitch:
+		o.Done = true
+		m.Objects[o.Pair].Done = true
+	}
+}
+
+// ResetObjects puts every key back and shuts every door and gate again
+func (m *Maze) ResetObjects() {
+	for _, o := range m.Objects {
+		o.Done = false
+	}
+}
+
+// reports whether a shut door would open for the player right now, because
+// they have its key
+func (m *Maze) unlockable(o *Object) bool {
+	return o.Kind == ObjectDoor && !o.Done && m.Objects[o.Pair].Done
+}
+
+// reports whether an object still needs using to open something up
+func (m *Maze) useful(o *Object) bool {
+	return (o.Kind == ObjectKey || o.Kind == ObjectSwitch) && !o.Done && !m.Objects[o.Pair].Done
+}
+
+// PlaceObjects puts doors and gates across the way out of a maze, each with a
+// key or switch somewhere the player can get to before it
+// The locks go in order along the shortest way out, and the opener for each
+// one goes somewhere reachable without getting through that lock or any after
+// it.  The ones before it can all be opened by then, so there's always a way
+// to open every lock in turn, whatever the maze looks like.
+func PlaceObjects(m *Maze, source rand.Source, start image.Point, doors, gates int) {
+	rng := rand.New(source)
+	path := m.Solution(start)
+	// Leave some room at both ends, the exit is the last two cells
+	var spots []image.Point
+	for _, p := range path[min(2, len(path)):max(0, len(path)-2)] {
+		if m.corridor(p) {
+			spots = append(spots, p)
+		}
+	}
+	locks := min(doors+gates, len(spots))
+	if locks == 0 {
+		return
+	}
+
+	// Which locks are doors and which are gates is shuffled along the path
+	kinds := make([]ObjectKind, 0, locks)
+	for i := range locks {
+		if i < doors {
+			kinds = append(kinds, ObjectDoor)
+		} else {
+			kinds = append(kinds, ObjectGate)
+		}
+	}
+	rng.Shuffle(len(kinds), func(i, j int) { kinds[i], kinds[j] = kinds[j], kinds[i] })
+	first := len(m.Objects) // There may be stairs already
+	for i, kind := range kinds {
+		spot := spots[(i+1)*len(spots)/(locks+1)]
+		m.Objects = append(m.Objects, &Object{Kind: kind, Coords: spot})
+	}
+
+	for i := first; i < first+locks; i++ {
+		lock := m.Objects[i]
+		// Where the player can get to with this lock and the ones after it shut
+		reach := m.reachable(start, func(p image.Point) bool {
+			for _, o := range m.Objects[i : first+locks] {
+				if o.Coords.Eq(p) {
+					return false
+				}
+			}
+			return true
+		})
+		opener := ObjectKey
+		if lock.Kind == ObjectGate {
+			opener = ObjectSwitch
+		}
+		lock.Pair = len(m.Objects)
+		m.Objects = append(m.Objects, &Object{
+			Kind:   opener,
+			Coords: m.pickSpot(rng, reach, start),
+			Pair:   i,
+		})
+	}
+}
+
+// picks a free cell for an opener, out of the way in a dead end if it can
+func (m *Maze) pickSpot(rng *rand.Rand, cells []image.Point, start image.Point) image.Point {
+	var free, ends []image.Point
+	for _, p := range cells {
+		if p.Eq(start) || p.Eq(m.Exit.Sub(image.Pt(0, 1))) || m.Object(p) != nil {
+			continue
+		}
+		free = append(free, p)
+		if m.ways(p) == 1 {
+			ends = append(ends, p)
+		}
+	}
+	if len(ends) > 0 {
+		return ends[rng.Intn(len(ends))]
+	}
+	if len(free) > 0 {
+		return free[rng.Intn(len(free))]
+	}
+	return start // Nowhere else to go, so it's picked up straight away
+}
+
+// reports whether there's a door or gate at a point, open or shut
+func (m *Maze) lock(p image.Point) bool {
+	o := m.Object(p)
+	return o != nil && o.Lock()
+}
+
+// reports whether a cell is a plain corridor, so a lock there blocks the way
+func (m *Maze) corridor(p image.Point) bool {
+	return m.ways(p) == 2 && m.Object(p) == nil
+}
+
+// counts the open cells next to a cell, ignoring objects
+func (m *Maze) ways(p image.Point) int {
+	n := 0
+	for _, d := range directions {
+		if m.Grid.In(p.Add(d)) && !m.Grid.Wall(p.Add(d)) {
+			n++
+		}
+	}
+	return n
+}
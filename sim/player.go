@@ -0,0 +1,109 @@
+package sim
+
+import (
+	"image"
+
+	"github.com/sinisterstuf/dynamo/timer"
+)
+
+// Player is the pixel the player controls
+type Player struct {
+	Coords  image.Point
+	TorchOn bool
+	Battery *Battery
+	Range   int          // How far the torch reaches on a full battery
+	Step    *timer.Timer // Cooldown between moves
+	Moved   bool
+	bumping bool // Whether the last move went into a wall
+}
+
+// NewPlayer initialises a new Player object with default values
+// The battery's drain and charge rates depend on the difficulty.
+// Its cooldowns run on the given level clock.
+func NewPlayer(d Difficulty, clock *timer.Scheduler) *Player {
+	return &Player{
+		Coords:  image.Pt(1, 1), // This is inset by 1 because 0,0 is a wall
+		TorchOn: true,           // Start with torch on so that the map is shown
+		Battery: NewBattery(d),
+		Range:   d.Range,
+		Step:    clock.Cooldown(),
+	}
+}
+
+// ToggleTorch switches the torch on or off
+// The torch can't be switched on when the battery is flat.
+func (p *Player) ToggleTorch() {
+	p.TorchOn = !p.TorchOn && !p.Battery.Empty()
+}
+
+// TorchRadius is how far the torch currently lights up around the Player
+// A fading battery gives a dimmer light, down to half of the full range.
+func (p *Player) TorchRadius() int {
+	return p.Range/2 + p.Range*p.Battery.Charge/p.Battery.Capacity/2
+}
+
+// UpdateTorch drains the battery while the torch is lit
+// The torch goes dark by itself once the battery runs out.
+func (p *Player) UpdateTorch() {
+	if p.TorchOn && !p.Battery.Discharge() {
+		p.TorchOn = false
+	}
+}
+
+// Move moves the Player in the given direction if possible
+// This includes checks for whether the move is legal at all, e.g. would collide
+// with a wall or a locked door, and using whatever is on the cell moved onto,
+// which might be a portal somewhere else.
+// It also includes special logic for movement speed when the key
+// is being tapped and when it's being held down.  It reports whether the
+// Player bumped into a wall or something locked.
+func (p *Player) Move(maze *Maze, dest image.Point, justPressed bool) bool {
+
+	// Still cooling down from last move, unless the key was tapped
+	if p.Step.Active() && !justPressed {
+		return false
+	}
+
+	// Don't move if the key is still being held in from the last level
+	if !p.Moved {
+		if justPressed {
+			p.Moved = true
+		} else { // Skip return so that lower-down "just pressed" logic runs
+			return false
+		}
+	}
+
+	// Even just attempting to move turns off the torch
+	p.TorchOn = false
+
+	// Do the actual move if legal
+	newCoords := p.Coords.Add(dest)
+	maze.Unlock(newCoords)
+	if maze.Open(newCoords) {
+		p.Coords = maze.Arrive(newCoords)
+		maze.Touch(newCoords)
+		p.bumping = false
+		p.Step.Reset(2) // short cooldown when holding down
+		if justPressed {
+			p.Step.Reset(15) // long first cooldown when tapping key
+		}
+		return false
+	}
+
+	// Walking into a wall only counts once for as long as the key is held
+	bumped := justPressed || !p.bumping
+	p.bumping = true
+	return bumped
+}
+
+// Climb takes the stairs if the Player is standing on some
+// It reports whether they did, and like moving it turns off the torch.
+func (p *Player) Climb(maze *Maze) bool {
+	to, ok := maze.Link(p.Coords)
+	if !ok || !maze.Open(to) {
+		return false
+	}
+	p.Coords = to
+	p.TorchOn = false
+	return true
+}
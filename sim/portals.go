@@ -0,0 +1,92 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+	"slices"
+)
+
+// PlacePortals puts pairs of portals in the maze that each lead to the other
+// Portals only go in dead ends, so nobody ever has to walk through one to get
+// anywhere and the maze can be solved just the same with them as without.
+// Each pair joins a dead end to one of the furthest ones away from it, which
+// makes for a shortcut worth taking, or at least a long way round saved.  Both
+// ends are on the same side of every door and gate, so portals are never a
+// way round one.
+func PlacePortals(m *Maze, source rand.Source, start image.Point, pairs int) {
+	if pairs <= 0 {
+		return
+	}
+	rng := rand.New(source)
+	above := m.Exit.Sub(image.Pt(0, 1))
+	var ends []image.Point
+	for k, wall := range m.Grid.Walls {
+		p := image.Pt(k%m.Grid.Size.X, k/m.Grid.Size.X)
+		if !wall && m.ways(p) == 1 && !p.Eq(start) && !p.Eq(above) && m.Object(p) == nil {
+			ends = append(ends, p)
+		}
+	}
+
+	for range pairs {
+		if len(ends) < 2 {
+			return
+		}
+		a := ends[rng.Intn(len(ends))]
+		ends = slices.DeleteFunc(ends, a.Eq)
+		// Somewhere in the far quarter of the dead ends that can be walked to
+		// without getting through anything locked
+		side := make([]bool, len(m.Grid.Walls))
+		for _, p := range m.reachable(a, func(p image.Point) bool { return !m.lock(p) }) {
+			side[m.Grid.Index(p)] = true
+		}
+		dist := m.steps(a)
+		var far []image.Point
+		for _, p := range ends {
+			if side[m.Grid.Index(p)] {
+				far = append(far, p)
+			}
+		}
+		if len(far) == 0 {
+			continue
+		}
+		slices.SortStableFunc(far, func(p, q image.Point) int {
+			return dist[m.Grid.Index(q)] - dist[m.Grid.Index(p)]
+		})
+		b := far[rng.Intn(max(1, len(far)/4))]
+		ends = slices.DeleteFunc(ends, b.Eq)
+
+		n := len(m.Objects)
+		m.Objects = append(m.Objects,
+			&Object{Kind: ObjectPortal, Coords: a, Pair: n + 1},
+			&Object{Kind: ObjectPortal, Coords: b, Pair: n},
+		)
+	}
+}
+
+// Arrive is where the player ends up by moving onto a point, which is the
+// other end if it's a portal
+func (m *Maze) Arrive(p image.Point) image.Point {
+	if o := m.Object(p); o != nil && o.Kind == ObjectPortal {
+		return m.Objects[o.Pair].Coords
+	}
+	return p
+}
+
+// counts the steps from a point to every cell that can be walked to from it,
+// taking the stairs but ignoring everything else in the way apart from walls
+// The result is indexed the same as the grid, with -1 for unreachable cells.
+func (m *Maze) steps(from image.Point) []int {
+	dist := make([]int, len(m.Grid.Walls))
+	for k := range dist {
+		dist[k] = -1
+	}
+	dist[m.Grid.Index(from)] = 0
+	for _, p := range m.reachable(from, func(image.Point) bool { return true }) {
+		for _, n := range m.links(p) {
+			if m.Grid.In(n) && !m.Grid.Wall(n) && dist[m.Grid.Index(n)] < 0 {
+				dist[m.Grid.Index(n)] = dist[m.Grid.Index(p)] + 1
+			}
+		}
+	}
+	return dist
+}
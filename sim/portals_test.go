@@ -0,0 +1,39 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+func TestPortalsStayOnOneSideOfLocks(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		g := gridFrom(
+			"###########",
+			"#.........#",
+			"##.#.#.#.##",
+			"###########",
+		)
+		door := image.Pt(5, 1)
+		m := &Maze{
+			Grid:    g,
+			Floors:  1,
+			Exit:    image.Pt(9, 4),
+			Objects: []*Object{{Kind: ObjectDoor, Coords: door, Pair: 1}, {Kind: ObjectKey, Coords: image.Pt(1, 1)}},
+		}
+		PlacePortals(m, rand.NewSource(seed), image.Pt(1, 1), 2)
+		portals := 0
+		for _, o := range m.Objects {
+			if o.Kind != ObjectPortal {
+				continue
+			}
+			portals++
+			if other := m.Objects[o.Pair]; (o.Coords.X < door.X) != (other.Coords.X < door.X) {
+				t.Errorf("seed %d: portal at %v goes through the door to %v", seed, o.Coords, other.Coords)
+			}
+		}
+		if portals == 0 {
+			t.Errorf("seed %d: no portals", seed)
+		}
+	}
+}
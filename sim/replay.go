@@ -0,0 +1,182 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReplayMagic is at the start of every replay file
+const ReplayMagic string = "DYNR"
+
+// ReplayVersion is the version of the replay file format that gets written
+// It's followed by the RulesVersion the replay was recorded under.
+const ReplayVersion byte = 1
+
+// MaxReplayTicks is the longest replay that gets read, ten hours of play
+// It stops a corrupt replay from asking for more memory than there is.
+const MaxReplayTicks int = 60 * 60 * 60 * 10
+
+// Replay is a recording of a run: how it started and every tick's Input
+// Because the simulation is deterministic this is enough to play the whole
+// run back exactly as it happened.
+type Replay struct {
+	Seed      int64
+	Level     int    // Difficulty level the run started at
+	Endless   bool   // Whether it was an endless mode run
+	Shifting  bool   // Whether it had shifting walls
+	Generator string // Name of the maze algorithm, empty to pick per level
+	Inputs    []Input
+}
+
+// NewReplay starts an empty recording of a run that's about to start
+func NewReplay(s *Sim) *Replay {
+	r := &Replay{Seed: s.Seed, Level: s.Level, Endless: s.Endless, Shifting: s.Shifting}
+	if s.Gen != nil {
+		r.Generator = s.Gen.Name()
+	}
+	return r
+}
+
+// Record adds one tick's Input to the end of the recording
+func (r *Replay) Record(in Input) {
+	r.Inputs = append(r.Inputs, in)
+}
+
+// Sim starts a new simulation in the same state the recorded run started in
+func (r *Replay) Sim() *Sim {
+	if r.Endless {
+		return NewEndless(r.Seed, GeneratorByName(r.Generator), r.Shifting)
+	}
+	return New(r.Seed, r.Level, GeneratorByName(r.Generator), r.Shifting)
+}
+
+// Play returns a Playback for feeding the recording through a simulation
+func (r *Replay) Play() *Playback {
+	return &Playback{Replay: r}
+}
+
+// WriteTo writes the replay in its compact file format
+// After a header the inputs are run-length encoded, because buttons tend to
+// stay the same for many ticks at a time.
+func (r *Replay) WriteTo(w io.Writer) (int64, error) {
+	buf := []byte(ReplayMagic)
+	buf = append(buf, ReplayVersion)
+	buf = binary.AppendUvarint(buf, uint64(RulesVersion))
+	buf = binary.AppendVarint(buf, r.Seed)
+	buf = binary.AppendUvarint(buf, uint64(r.Level))
+	var flags byte
+	if r.Endless {
+		flags |= 1
+	}
+	if r.Shifting {
+		flags |= 2
+	}
+	buf = append(buf, flags)
+	buf = binary.AppendUvarint(buf, uint64(len(r.Generator)))
+	buf = append(buf, r.Generator...)
+	for i := 0; i < len(r.Inputs); {
+		run := 1
+		for i+run < len(r.Inputs) && r.Inputs[i+run] == r.Inputs[i] {
+			run++
+		}
+		buf = binary.AppendUvarint(buf, uint64(run))
+		buf = binary.AppendUvarint(buf, uint64(r.Inputs[i]))
+		i += run
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadReplay reads a replay written by WriteTo
+func ReadReplay(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(ReplayMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("reading replay header: %w", err)
+	}
+	if string(header[:len(ReplayMagic)]) != ReplayMagic {
+		return nil, errors.New("not a replay file")
+	}
+	if v := header[len(ReplayMagic)]; v != ReplayVersion {
+		return nil, fmt.Errorf("unsupported replay version %d", v)
+	}
+	rules, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("reading replay rules: %w", err)
+	}
+	if rules != uint64(RulesVersion) {
+		return nil, fmt.Errorf("replay is for version %d of the rules, not %d", rules, RulesVersion)
+	}
+
+	rep := &Replay{}
+	if rep.Seed, err = binary.ReadVarint(br); err != nil {
+		return nil, fmt.Errorf("reading replay seed: %w", err)
+	}
+	level, err := binary.ReadUvarint(br)
+	if err != nil || level >= uint64(len(LevelNames)) {
+		return nil, fmt.Errorf("bad replay level %d: %v", level, err)
+	}
+	rep.Level = int(level)
+	flags, err := br.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("reading replay flags: %w", err)
+	}
+	rep.Endless = flags&1 != 0
+	rep.Shifting = flags&2 != 0
+	nameLen, err := binary.ReadUvarint(br)
+	if err != nil || nameLen > 64 {
+		return nil, fmt.Errorf("bad replay generator: %v", err)
+	}
+	name := make([]byte, nameLen)
+	if _, err := io.ReadFull(br, name); err != nil {
+		return nil, fmt.Errorf("reading replay generator: %w", err)
+	}
+	rep.Generator = string(name)
+	if rep.Generator != "" && GeneratorByName(rep.Generator) == nil {
+		return nil, fmt.Errorf("unknown maze generator %q", rep.Generator)
+	}
+
+	for {
+		run, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return rep, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading replay inputs: %w", err)
+		}
+		in, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading replay inputs: %w", err)
+		}
+		if run > uint64(MaxReplayTicks-len(rep.Inputs)) {
+			return nil, fmt.Errorf("replay is longer than %d ticks", MaxReplayTicks)
+		}
+		for ; run > 0; run-- {
+			rep.Inputs = append(rep.Inputs, Input(in))
+		}
+	}
+}
+
+// Playback steps through the inputs of a Replay one tick at a time
+type Playback struct {
+	Replay *Replay
+	Tick   int // Index of the next input to play
+}
+
+// Next returns the next tick's Input, or false when the recording is over
+func (p *Playback) Next() (Input, bool) {
+	if p.Done() {
+		return 0, false
+	}
+	in := p.Replay.Inputs[p.Tick]
+	p.Tick++
+	return in, true
+}
+
+// Done reports whether the whole recording has been played
+func (p *Playback) Done() bool {
+	return p.Tick >= len(p.Replay.Inputs)
+}
@@ -0,0 +1,129 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// plays a run for a number of ticks and records it
+// It walks the way out of each maze a tap at a time, stopping now
+// and then to crank the dynamo, and goes straight on to the next one.
+func recordRun(s *Sim, ticks int) *Replay {
+	r := NewReplay(s)
+	for tick := range ticks {
+		var in Input
+		switch {
+		case tick%2 == 1: // Let go between taps
+		case s.Done:
+			in = in.With(ButtonNext)
+		case tick%50 < 6:
+			in = in.With(ButtonCrankA + Button(tick/2%2))
+		default:
+			in = in.With(towardsExit(s))
+		}
+		r.Record(in)
+		s.Step(in)
+	}
+	return r
+}
+
+// the button for the next step of the way out
+func towardsExit(s *Sim) Button {
+	path := s.Maze.Solution(s.Player.Coords)
+	if len(path) == 0 {
+		return ButtonDown
+	}
+	return button(s.Maze, s.Player.Coords, path[0])
+}
+
+// checks that two simulations got to the same state
+func sameRun(t *testing.T, got, want *Sim) {
+	t.Helper()
+	if got.Ticks != want.Ticks || got.Level != want.Level || got.Win != want.Win {
+		t.Errorf("at tick %d of level %d, want tick %d of level %d", got.Ticks, got.Level, want.Ticks, want.Level)
+	}
+	if got.Player.Coords != want.Player.Coords || got.Player.Battery.Charge != want.Player.Battery.Charge {
+		t.Errorf("player at %v with %d charge, want at %v with %d", got.Player.Coords, got.Player.Battery.Charge, want.Player.Coords, want.Player.Battery.Charge)
+	}
+	if !slices.Equal(got.Maze.Grid.Walls, want.Maze.Grid.Walls) || !slices.Equal(got.Maze.Explored, want.Maze.Explored) {
+		t.Error("maze is different")
+	}
+	if len(got.Enemies) != len(want.Enemies) {
+		t.Fatalf("%d minotaurs, want %d", len(got.Enemies), len(want.Enemies))
+	}
+	for i, e := range got.Enemies {
+		if e.Coords != want.Enemies[i].Coords {
+			t.Errorf("minotaur %d at %v, want %v", i, e.Coords, want.Enemies[i].Coords)
+		}
+	}
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	runs := map[string]*Sim{
+		"levels":    New(7, LevelEasy, nil, false),
+		"endless":   NewEndless(7, nil, true),
+		"generator": New(7, LevelMedium, GeneratorByName(Wilson{}.Name()), true),
+	}
+	for name, s := range runs {
+		t.Run(name, func(t *testing.T) {
+			rec := recordRun(s, 3000)
+			var buf bytes.Buffer
+			if _, err := rec.WriteTo(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadReplay(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, rec) {
+				t.Errorf("read back %+v, want %+v", got, rec)
+			}
+		})
+	}
+}
+
+func TestReplayPlaysBackTheSameRun(t *testing.T) {
+	s := NewEndless(42, nil, true)
+	rec := recordRun(s, 5000)
+	if s.Depth == 0 {
+		t.Fatal("didn't get out of the first maze, so there's not much to play back")
+	}
+
+	again := rec.Sim()
+	play := rec.Play()
+	for in, ok := play.Next(); ok; in, ok = play.Next() {
+		again.Step(in)
+	}
+	sameRun(t, again, s)
+}
+
+func TestReadReplayRejectsJunk(t *testing.T) {
+	for _, junk := range []string{"", "DYN", "NOPE\x01", ReplayMagic + "\x09"} {
+		if _, err := ReadReplay(bytes.NewBufferString(junk)); err == nil {
+			t.Errorf("read %q without an error", junk)
+		}
+	}
+}
+
+func TestReadReplayRejectsOtherRules(t *testing.T) {
+	var buf bytes.Buffer
+	NewReplay(New(1, LevelBeginner, nil, false)).WriteTo(&buf)
+	data := buf.Bytes()
+	data[len(ReplayMagic)+1]++ // The rules version comes straight after the file version
+	if _, err := ReadReplay(bytes.NewReader(data)); err == nil {
+		t.Error("read a replay for other rules without an error")
+	}
+}
+
+func TestReadReplayRejectsOverlongRuns(t *testing.T) {
+	var buf bytes.Buffer
+	NewReplay(New(1, LevelBeginner, nil, false)).WriteTo(&buf)
+	data := binary.AppendUvarint(buf.Bytes(), 1<<62)
+	data = binary.AppendUvarint(data, 0)
+	if _, err := ReadReplay(bytes.NewReader(data)); err == nil {
+		t.Error("read a replay longer than the limit without an error")
+	}
+}
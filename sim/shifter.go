@@ -0,0 +1,99 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+)
+
+// ShiftKind is what makes a shifting wall move
+type ShiftKind int
+
+// Shifting walls either keep a beat of their own or answer to the torch
+const (
+	ShiftRhythm ShiftKind = iota // Opens and shuts again on a steady beat
+	ShiftTorch                   // Opens or shuts each time the torch is switched on
+)
+
+// Shifter is a piece of wall that can open up into a gap and shut again
+// Every Shifter is a wall in the maze as it was generated, so with all of them
+// shut there is still a way to the exit from anywhere, and opening them only
+// ever makes new ways.
+type Shifter struct {
+	Kind   ShiftKind   `json:"kind"`
+	Coords image.Point `json:"coords"`
+	Period int         `json:"period"` // Ticks of a rhythm wall's beat, it's open for the second half
+	Phase  int         `json:"phase"`  // Ticks into its beat a rhythm wall starts at
+	Open   bool        `json:"open"`   // Whether a torch wall has been flipped open
+}
+
+// shiftBeat is the shortest beat of a rhythm wall, the longest is twice that
+const shiftBeat int = 180
+
+// PlaceShifters picks pieces of wall to shift, every third one a torch wall
+// Only walls between two corridor cells in a line can shift, so that opening
+// one joins up two corridors instead of making a hole in the side of one.
+func PlaceShifters(m *Maze, source rand.Source, count int) {
+	if count <= 0 {
+		return
+	}
+	rng := rand.New(source)
+	var spots []image.Point
+	for y := 1; y < m.Grid.Size.Y-1; y++ {
+		for x := 1; x < m.Grid.Size.X-1; x++ {
+			if p := image.Pt(x, y); m.Grid.Wall(p) && m.between(p) {
+				spots = append(spots, p)
+			}
+		}
+	}
+	rng.Shuffle(len(spots), func(i, j int) { spots[i], spots[j] = spots[j], spots[i] })
+	for i, p := range spots[:min(count, len(spots))] {
+		sh := &Shifter{Coords: p, Period: shiftBeat + rng.Intn(shiftBeat)}
+		sh.Phase = rng.Intn(sh.Period)
+		if i%3 == 2 {
+			sh.Kind = ShiftTorch
+		}
+		m.Shifters = append(m.Shifters, sh)
+	}
+}
+
+// reports whether a wall has corridors on both sides of it, across or down
+func (m *Maze) between(p image.Point) bool {
+	open := func(d image.Point) bool { return !m.Grid.Wall(p.Add(d)) }
+	return open(image.Pt(-1, 0)) && open(image.Pt(1, 0)) ||
+		open(image.Pt(0, -1)) && open(image.Pt(0, 1))
+}
+
+// Shift opens and shuts the shifting walls for a tick into an attempt
+// A wall never shuts on anything standing in the gap, it waits until they've
+// moved out of the way.
+func (m *Maze) Shift(tick int, occupied func(image.Point) bool) {
+	for _, sh := range m.Shifters {
+		open := sh.Open
+		if sh.Kind == ShiftRhythm {
+			open = (tick+sh.Phase)%sh.Period >= sh.Period/2
+		}
+		if !open && occupied(sh.Coords) {
+			continue
+		}
+		m.Grid.Walls[m.Grid.Index(sh.Coords)] = !open
+	}
+}
+
+// FlipTorchWalls opens the torch walls that are shut and shuts the open ones,
+// they only move once Shift next runs
+func (m *Maze) FlipTorchWalls() {
+	for _, sh := range m.Shifters {
+		if sh.Kind == ShiftTorch {
+			sh.Open = !sh.Open
+		}
+	}
+}
+
+// ResetShifters shuts all the shifting walls again, for starting over
+// Nothing may be standing in any of them.
+func (m *Maze) ResetShifters() {
+	for _, sh := range m.Shifters {
+		sh.Open = false
+		m.Grid.Walls[m.Grid.Index(sh.Coords)] = true
+	}
+}
@@ -0,0 +1,319 @@
+// Package sim is the simulation of the game rules, separate from ebiten
+// It is advanced one tick at a time from an Input snapshot and is fully
+// deterministic for a given seed, so it can run headless in tests, replays
+// and bots just the same as in the real game.
+package sim
+
+import (
+	"image"
+	"math/rand"
+
+	"github.com/sinisterstuf/dynamo/timer"
+)
+
+// RulesVersion is the version of the game rules, including how mazes are
+// generated from a seed
+// It goes up whenever the same seed and inputs would play out differently, so
+// replays and ghosts from before can be turned down instead of going wrong.
+const RulesVersion int = 1
+
+// mazeSeedStride spreads out the seeds of the mazes at each depth of a run
+const mazeSeedStride int64 = 0x5DEECE66D
+
+// HintSteps is how many steps of the way out a hint shows
+const HintSteps int = 10
+
+// HintTicks is how long a hint is shown for
+const HintTicks int = 90
+
+// ExitWalk is how far the player walks out past the exit before the next
+// level starts, enough to walk off the bottom of the screen
+const ExitWalk int = 48
+
+// moves maps movement buttons to directions, in the order they are handled
+var moves = []struct {
+	button Button
+	dir    image.Point
+}{
+	{ButtonDown, image.Pt(0, 1)},
+	{ButtonUp, image.Pt(0, -1)},
+	{ButtonLeft, image.Pt(-1, 0)},
+	{ButtonRight, image.Pt(1, 0)},
+}
+
+// Sim is the whole state of a run through the game
+type Sim struct {
+	Seed       int64      // Seed for the random source, which makes the run repeatable
+	Level      int        // Named difficulty level the current maze is at
+	Depth      int        // How many mazes into the game, counting from the first beginner one
+	Endless    bool       // Whether the difficulty keeps ramping up past LevelExtreme
+	Shifting   bool       // Whether some of the walls open and shut as the player goes
+	Difficulty Difficulty // How hard the current maze is
+	Gen        Generator  // Maze algorithm, or nil to pick one per level
+	Maze       *Maze
+	Player     *Player
+	Enemies    []*Enemy
+	Hint       []image.Point    // Next steps of the way out while a hint is shown
+	HintsLeft  int              // How many more hints the player can have in this maze
+	Win        bool             // Whether the player has reached the exit
+	Done       bool             // Whether the player has walked out and can go on to the next maze
+	Over       bool             // Whether time ran out, which ends the run
+	Clock      *timer.Scheduler // Runs timers for the current level
+	Ticks      int              // How many ticks have been simulated in the run
+	Attempt    int              // How many ticks into the current attempt at the maze
+	Trail      *Ghost           // Route of the current attempt at the maze
+	Stats      Stats            // How the player is getting on in the current maze
+	History    []Stats          // How the player got through each maze of the run
+	Source     *Source
+	input      Input        // Buttons held during the current tick
+	prev       Input        // Buttons held during the previous tick
+	hint       *timer.Timer // Until the hint is hidden again
+}
+
+// New starts a run of the game from a difficulty level
+func New(seed int64, level int, gen Generator, shifting bool) *Sim {
+	s := &Sim{
+		Seed:     seed,
+		Depth:    level,
+		Shifting: shifting,
+		Gen:      gen,
+		Source:   NewSource(seed, 0),
+	}
+	s.StartLevel()
+	return s
+}
+
+// NewEndless starts a run of the game that gets harder for as long as the
+// player can keep up, from the first beginner maze
+func NewEndless(seed int64, gen Generator, shifting bool) *Sim {
+	s := &Sim{
+		Seed:     seed,
+		Endless:  true,
+		Shifting: shifting,
+		Gen:      gen,
+		Source:   NewSource(seed, 0),
+	}
+	s.StartLevel()
+	return s
+}
+
+// Step advances the simulation by one tick with the buttons that are held
+func (s *Sim) Step(in Input) {
+	s.input = in
+	s.step()
+	s.prev = in
+	s.Ticks++
+	s.Attempt++
+}
+
+// JustPressed reports whether a button was pressed down during this tick
+func (s *Sim) JustPressed(b Button) bool {
+	return s.input.Held(b) && !s.prev.Held(b)
+}
+
+// Runs the game rules for one tick
+func (s *Sim) step() {
+	if s.Over {
+		return
+	}
+	if s.JustPressed(ButtonRestart) {
+		s.RestartLevel()
+		return
+	}
+
+	if s.Player.Coords.Eq(s.Maze.Exit) && !s.Win {
+		s.Win = true
+		s.Trail.Finish(s.Attempt)
+		s.History = append(s.History, s.Stats)
+	}
+
+	// Walk out of the maze, then wait to be told to go on to the next one
+	if s.Win {
+		if s.Player.Coords.Y <= s.Maze.Exit.Y+ExitWalk {
+			s.Player.Coords.Y++
+		} else {
+			s.Done = true
+		}
+		if s.Done && s.JustPressed(ButtonNext) {
+			s.NextLevel()
+		}
+		return
+	}
+
+	// Running out of time in a maze ends the run
+	s.Stats.Ticks++
+	if limit := s.TimeLimit(); limit > 0 && s.Stats.Ticks >= limit {
+		s.Over = true
+		return
+	}
+
+	// Movement controls
+	for _, m := range moves {
+		if s.input.Held(m.button) {
+			from := s.Player.Coords
+			if s.Player.Move(s.Maze, m.dir, s.JustPressed(m.button)) {
+				s.Stats.Bumps++
+			}
+			if !s.Player.Coords.Eq(from) {
+				s.Stats.Steps++
+			}
+		}
+	}
+	s.Trail.Add(s.Attempt, s.Player.Coords)
+
+	// The action button takes the stairs when there are any, otherwise it's
+	// the torch switch
+	if s.JustPressed(ButtonTorch) {
+		if s.Player.Climb(s.Maze) {
+			s.Stats.Steps++
+			s.Trail.Add(s.Attempt, s.Player.Coords)
+		} else {
+			s.Player.ToggleTorch()
+			if s.Shifting && s.Player.TorchOn {
+				s.Maze.FlipTorchWalls()
+			}
+		}
+	}
+	s.Player.UpdateTorch()
+	if s.Player.TorchOn {
+		s.Stats.TorchTicks++
+	}
+
+	if s.JustPressed(ButtonHint) {
+		s.ShowHint()
+	}
+
+	// Crank the dynamo to charge the torch battery
+	for _, b := range []Button{ButtonCrankA, ButtonCrankB} {
+		if s.input.Held(b) {
+			s.Player.Battery.Crank(b, s.JustPressed(b))
+		}
+	}
+
+	// Minotaurs hunt the player, getting caught means starting over
+	for _, e := range s.Enemies {
+		e.Update(s.Maze.Grid, s.Player.Coords, s.Player.TorchOn)
+		if e.Caught(s.Player.Coords) {
+			s.RestartLevel()
+			return
+		}
+	}
+
+	if s.Shifting {
+		s.Maze.Shift(s.Attempt, s.occupied)
+	}
+
+	// Light up the maze and remember what the player has seen
+	s.Maze.Visit(s.Player.Coords)
+	radius := 0
+	if s.Player.TorchOn {
+		radius = s.Player.TorchRadius()
+	}
+	s.Maze.Illuminate(s.Player.Coords, radius)
+
+	s.Clock.Update()
+}
+
+// NextLevel sets up the next level of the game
+// It handles things like increasing difficulty and resetting the Player state
+func (s *Sim) NextLevel() {
+	s.Depth++
+	s.StartLevel()
+}
+
+// StartLevel sets up a new maze for the current difficulty level
+// The maze has a random source of its own, so it's always the same maze for
+// a seed and depth however the run got there, which makes it raceable.
+func (s *Sim) StartLevel() {
+	s.Level = min(s.Depth, LevelExtreme)
+	s.Difficulty = Difficulties.At(s.difficultyDepth())
+	s.Win = false
+	s.Done = false
+	s.Clock = timer.New()
+	s.Player = NewPlayer(s.Difficulty, s.Clock)
+	s.Maze = NewMaze(s.MazeGenerator(), rand.NewSource(s.MazeSeed()), s.Player.Coords, s.Difficulty) // also resets explored memory
+	s.Enemies = SpawnEnemies(s.Maze.Grid, s.Player.Coords, s.Maze.FloorRects(), s.Difficulty, s.Source, s.Clock)
+	s.Hint = nil
+	s.HintsLeft = s.Difficulty.Hints
+	s.Attempt = 0
+	s.Trail = NewGhost(s)
+	s.Stats = Stats{Depth: s.Depth, Level: s.Level, Par: s.Maze.Par(s.Player.Coords)}
+}
+
+// the depth on the difficulty curve, which only goes past LevelExtreme in
+// endless mode
+func (s *Sim) difficultyDepth() int {
+	if s.Endless {
+		return s.Depth
+	}
+	return min(s.Depth, LevelExtreme)
+}
+
+// TimeLimit is how many ticks the player has to get through the current
+// maze, 0 if there's no limit
+func (s *Sim) TimeLimit() int {
+	return s.Difficulty.TimePerStep * s.Stats.Par
+}
+
+// ShowHint shows the next few steps of the shortest way out for a while
+// Hints are limited per maze and cost points.
+func (s *Sim) ShowHint() {
+	if s.HintsLeft <= 0 {
+		return
+	}
+	s.HintsLeft--
+	s.Stats.Hints++
+	path := s.Maze.Solution(s.Player.Coords)
+	s.Hint = path[:min(len(path), HintSteps)]
+	if s.hint != nil {
+		s.hint.Stop()
+	}
+	s.hint = s.Clock.After(HintTicks, func() { s.Hint = nil })
+}
+
+// MazeSeed is the seed for generating the maze at the current depth
+func (s *Sim) MazeSeed() int64 {
+	return s.Seed + int64(s.Depth)*mazeSeedStride
+}
+
+// RestartLevel puts everything in the current maze back where it started
+// The maze itself and what the player has explored of it stay the same, but
+// keys go back and doors and gates shut again.
+func (s *Sim) RestartLevel() {
+	if s.Win && len(s.History) > 0 {
+		// Take back the win, it counts again once the player gets back out
+		s.History = s.History[:len(s.History)-1]
+	}
+	s.Win = false
+	s.Maze.ResetObjects()
+	s.Player.Step.Stop()
+	s.Player = NewPlayer(s.Difficulty, s.Clock)
+	s.Hint = nil
+	for _, e := range s.Enemies {
+		e.Reset()
+	}
+	s.Maze.ResetShifters()
+	s.Attempt = 0
+	s.Trail = NewGhost(s)
+}
+
+// reports whether the player or a minotaur is standing at a point
+func (s *Sim) occupied(p image.Point) bool {
+	if s.Player.Coords.Eq(p) {
+		return true
+	}
+	for _, e := range s.Enemies {
+		if e.Coords.Eq(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MazeGenerator returns the algorithm for generating the current level's maze
+func (s *Sim) MazeGenerator() Generator {
+	if s.Gen != nil {
+		return s.Gen
+	}
+	return s.Difficulty.Generator
+}
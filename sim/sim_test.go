@@ -0,0 +1,258 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+// starts a beginner run in a hand-made maze from rows of text, # for walls
+// The exit is below the open cell in the bottom row.
+func simFrom(rows ...string) *Sim {
+	s := New(1, LevelBeginner, nil, false)
+	g := gridFrom(rows...)
+	var exit image.Point
+	for x := range g.Size.X {
+		if !g.Wall(image.Pt(x, g.Size.Y-1)) {
+			exit = image.Pt(x, g.Size.Y)
+		}
+	}
+	s.Maze = &Maze{
+		Grid:      g,
+		Floors:    1,
+		FloorSize: g.Size,
+		Exit:      exit,
+		Lit:       make([]bool, len(g.Walls)),
+		Explored:  make([]bool, len(g.Walls)),
+	}
+	s.Stats.Par = len(s.Maze.Solution(s.Player.Coords))
+	return s
+}
+
+// a short way out from the start, right then down
+var hook = []string{
+	"#####",
+	"#...#",
+	"###.#",
+}
+
+// presses a button for one tick and lets it go again on the next
+func tap(s *Sim, b Button) {
+	s.Step(Input(0).With(b))
+	s.Step(0)
+}
+
+// holds a button down for a number of ticks
+func hold(s *Sim, b Button, ticks int) {
+	for range ticks {
+		s.Step(Input(0).With(b))
+	}
+}
+
+func TestTapMovesOneCell(t *testing.T) {
+	s := simFrom(hook...)
+	tap(s, ButtonRight)
+	if want := image.Pt(2, 1); s.Player.Coords != want {
+		t.Errorf("at %v, want %v", s.Player.Coords, want)
+	}
+	if s.Stats.Steps != 1 {
+		t.Errorf("%d steps, want 1", s.Stats.Steps)
+	}
+	if s.Player.TorchOn {
+		t.Error("torch still on after moving")
+	}
+}
+
+func TestHoldingKeepsMoving(t *testing.T) {
+	s := simFrom(
+		"#########",
+		"#.......#",
+		"#######.#",
+	)
+	hold(s, ButtonRight, 15)
+	if want := image.Pt(2, 1); s.Player.Coords != want {
+		t.Fatalf("at %v during the first cooldown, want %v", s.Player.Coords, want)
+	}
+	hold(s, ButtonRight, 10)
+	if want := image.Pt(7, 1); s.Player.Coords != want {
+		t.Errorf("at %v after holding, want %v", s.Player.Coords, want)
+	}
+}
+
+func TestWallsBlockAndBumpOncePerPress(t *testing.T) {
+	s := simFrom(hook...)
+	hold(s, ButtonUp, 30)
+	if want := image.Pt(1, 1); s.Player.Coords != want {
+		t.Errorf("walked through a wall to %v", s.Player.Coords)
+	}
+	if s.Stats.Bumps != 1 {
+		t.Errorf("%d bumps holding into a wall, want 1", s.Stats.Bumps)
+	}
+	s.Step(0)
+	tap(s, ButtonUp)
+	tap(s, ButtonLeft)
+	if s.Stats.Bumps != 3 {
+		t.Errorf("%d bumps after two more taps, want 3", s.Stats.Bumps)
+	}
+	if s.Stats.Steps != 0 {
+		t.Errorf("%d steps without moving, want 0", s.Stats.Steps)
+	}
+}
+
+func TestWinningAndGoingOnToTheNextMaze(t *testing.T) {
+	s := simFrom(hook...)
+	for _, b := range []Button{ButtonRight, ButtonRight, ButtonDown} {
+		tap(s, b)
+	}
+	if s.Win {
+		t.Fatal("won before reaching the exit")
+	}
+	tap(s, ButtonDown)
+	if !s.Win {
+		t.Fatalf("at %v, want to have won at the exit %v", s.Player.Coords, s.Maze.Exit)
+	}
+	if len(s.History) != 1 || s.History[0].Steps != 4 {
+		t.Fatalf("history %+v, want one maze of 4 steps", s.History)
+	}
+
+	// Pressing next does nothing until the player has walked out
+	tap(s, ButtonNext)
+	if s.Depth != LevelBeginner {
+		t.Fatal("went on before walking out")
+	}
+	for range ExitWalk + 2 {
+		s.Step(0)
+	}
+	if !s.Done {
+		t.Fatalf("at %v, want to have walked out", s.Player.Coords)
+	}
+	maze := s.Maze
+	tap(s, ButtonNext)
+	if s.Depth != LevelBeginner+1 || s.Maze == maze {
+		t.Fatalf("at depth %d, want a new maze at depth %d", s.Depth, LevelBeginner+1)
+	}
+	if s.Win || s.Done || s.Player.Coords != image.Pt(1, 1) || s.Stats.Steps != 0 {
+		t.Errorf("next maze didn't start afresh: %+v", s.Stats)
+	}
+	if len(s.History) != 1 {
+		t.Errorf("%d mazes in history, want 1", len(s.History))
+	}
+}
+
+func TestGettingCaughtStartsTheMazeOver(t *testing.T) {
+	s := simFrom(
+		"#######",
+		"#.....#",
+		"#####.#",
+	)
+	tap(s, ButtonRight)
+	tap(s, ButtonTorch)
+	start := image.Pt(5, 1)
+	s.Enemies = []*Enemy{NewEnemy(start, 1, rand.NewSource(1), s.Clock)}
+	for range 10 {
+		s.Step(0)
+	}
+	if want := image.Pt(1, 1); s.Player.Coords != want || s.Enemies[0].Coords != start {
+		t.Errorf("player at %v and minotaur at %v, want back at %v and %v", s.Player.Coords, s.Enemies[0].Coords, want, start)
+	}
+}
+
+func TestRestartGoesBackToTheStart(t *testing.T) {
+	s := simFrom(hook...)
+	tap(s, ButtonRight)
+	tap(s, ButtonRestart)
+	if want := image.Pt(1, 1); s.Player.Coords != want {
+		t.Errorf("at %v after restarting, want %v", s.Player.Coords, want)
+	}
+	tap(s, ButtonRight)
+	if want := image.Pt(2, 1); s.Player.Coords != want {
+		t.Errorf("at %v, want to be able to move again to %v", s.Player.Coords, want)
+	}
+}
+
+func TestRestartingAfterWinningTakesItBack(t *testing.T) {
+	s := simFrom(hook...)
+	for range 2 {
+		for _, b := range []Button{ButtonRight, ButtonRight, ButtonDown, ButtonDown} {
+			tap(s, b)
+		}
+		if !s.Win {
+			t.Fatal("didn't win")
+		}
+		tap(s, ButtonRestart)
+	}
+	if len(s.History) != 0 {
+		t.Errorf("%d mazes in history after taking back both wins, want 0", len(s.History))
+	}
+	for _, b := range []Button{ButtonRight, ButtonRight, ButtonDown, ButtonDown} {
+		tap(s, b)
+	}
+	if len(s.History) != 1 {
+		t.Errorf("%d mazes in history after winning again, want 1", len(s.History))
+	}
+}
+
+func TestRunningOutOfTimeEndsTheRun(t *testing.T) {
+	s := simFrom(hook...)
+	s.Difficulty.TimePerStep = 2
+	limit := s.TimeLimit()
+	for range limit - 1 {
+		s.Step(0)
+	}
+	if s.Over {
+		t.Fatal("over before the time limit")
+	}
+	s.Step(0)
+	if !s.Over {
+		t.Fatalf("not over after %d ticks", limit)
+	}
+	tap(s, ButtonRight)
+	if s.Player.Coords != image.Pt(1, 1) {
+		t.Error("moved after the run was over")
+	}
+}
+
+// Walking the solution out of generated mazes one tap at a time gets through
+// each of them in par, keys, portals and all
+func TestSolvingGeneratedMazes(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		s := New(seed, LevelBeginner, nil, false)
+		for level := LevelBeginner; level <= LevelExtreme; level++ {
+			s.Enemies = nil // Minotaurs are tested on their own
+			for range 1000 {
+				if s.Win {
+					break
+				}
+				path := s.Maze.Solution(s.Player.Coords)
+				if len(path) == 0 {
+					t.Fatalf("seed %d level %d: no way out from %v", seed, level, s.Player.Coords)
+				}
+				tap(s, button(s.Maze, s.Player.Coords, path[0]))
+			}
+			if !s.Win || s.Level != level {
+				t.Fatalf("seed %d level %d: didn't get out", seed, level)
+			}
+			if s.Stats.Steps != s.Stats.Par {
+				t.Errorf("seed %d level %d: %d steps, par %d", seed, level, s.Stats.Steps, s.Stats.Par)
+			}
+			for !s.Done {
+				s.Step(0)
+			}
+			tap(s, ButtonNext)
+		}
+		if s.Level != LevelExtreme {
+			t.Errorf("seed %d: went past the last level to %d", seed, s.Level)
+		}
+	}
+}
+
+// the button that gets from one cell to the next, through a portal if there's
+// one in the way, or up or down the stairs
+func button(m *Maze, from, to image.Point) Button {
+	for _, mv := range moves {
+		if m.Arrive(from.Add(mv.dir)) == to {
+			return mv.button
+		}
+	}
+	return ButtonTorch
+}
@@ -0,0 +1,312 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/sinisterstuf/dynamo/timer"
+)
+
+// maxDraws is more random numbers than any real run draws, so restoring a
+// corrupt snapshot doesn't take forever
+const maxDraws uint64 = 1 << 32
+
+// Snapshot is the state of a run at one tick, for saving and carrying on later
+// The random source is kept as its seed and number of draws, and timers as
+// the ticks they have left, so the run carries on exactly as it would have.
+type Snapshot struct {
+	Seed      int64           `json:"seed"`
+	Draws     uint64          `json:"draws"`
+	Level     int             `json:"level"`
+	Depth     int             `json:"depth"`
+	Endless   bool            `json:"endless,omitempty"`
+	Shifting  bool            `json:"shifting,omitempty"`
+	Generator string          `json:"generator,omitempty"` // Empty to pick one per level
+	Ticks     int             `json:"ticks"`
+	Attempt   int             `json:"attempt"`
+	Win       bool            `json:"win"`
+	Done      bool            `json:"done"`
+	Over      bool            `json:"over,omitempty"`
+	HintsLeft int             `json:"hintsLeft"`
+	Input     Input           `json:"input"` // Buttons held on the last tick
+	Maze      MazeSnapshot    `json:"maze"`
+	Player    PlayerSnapshot  `json:"player"`
+	Enemies   []EnemySnapshot `json:"enemies"`
+	Trail     []GhostStep     `json:"trail"` // Route of the current attempt, for racing it later
+	Stats     Stats           `json:"stats"`
+	History   []Stats         `json:"history"`
+}
+
+// MazeSnapshot is the layout of a maze and what the player has seen of it
+// Cells are written as rows of text, # for walls and explored cells.
+type MazeSnapshot struct {
+	Size     image.Point `json:"size"`
+	Floors   int         `json:"floors,omitempty"` // Stacked in Size, 0 for the one
+	Exit     image.Point `json:"exit"`
+	Walls    []string    `json:"walls"`
+	Explored []string    `json:"explored"`
+	Objects  []Object    `json:"objects,omitempty"`
+	Shifters []Shifter   `json:"shifters,omitempty"`
+}
+
+// PlayerSnapshot is the state of the Player
+type PlayerSnapshot struct {
+	Coords  image.Point `json:"coords"`
+	TorchOn bool        `json:"torchOn"`
+	Charge  int         `json:"charge"`
+	Step    int         `json:"step"` // Ticks left of the move cooldown
+	Moved   bool        `json:"moved"`
+}
+
+// EnemySnapshot is the state of an Enemy
+type EnemySnapshot struct {
+	Coords  image.Point   `json:"coords"`
+	Start   image.Point   `json:"start"`
+	Path    []image.Point `json:"path"`
+	Chasing bool          `json:"chasing"`
+	Step    int           `json:"step"` // Ticks left until its next step
+}
+
+// Snapshot takes a copy of the state of the run
+func (s *Sim) Snapshot() *Snapshot {
+	snap := &Snapshot{
+		Seed:      s.Seed,
+		Draws:     s.Source.Draws,
+		Level:     s.Level,
+		Depth:     s.Depth,
+		Endless:   s.Endless,
+		Shifting:  s.Shifting,
+		Over:      s.Over,
+		HintsLeft: s.HintsLeft,
+		Ticks:     s.Ticks,
+		Attempt:   s.Attempt,
+		Win:       s.Win,
+		Done:      s.Done,
+		Input:     s.prev,
+		Maze: MazeSnapshot{
+			Size:     s.Maze.Grid.Size,
+			Exit:     s.Maze.Exit,
+			Walls:    encodeCells(s.Maze.Grid.Size, s.Maze.Grid.Walls),
+			Explored: encodeCells(s.Maze.Grid.Size, s.Maze.Explored),
+			Floors:   s.Maze.Floors,
+			Objects:  s.Maze.objects(),
+			Shifters: s.Maze.shifters(),
+		},
+		Player: PlayerSnapshot{
+			Coords:  s.Player.Coords,
+			TorchOn: s.Player.TorchOn,
+			Charge:  s.Player.Battery.Charge,
+			Step:    s.Player.Step.Remaining,
+			Moved:   s.Player.Moved,
+		},
+		Trail:   s.Trail.Steps,
+		Stats:   s.Stats,
+		History: s.History,
+	}
+	if s.Gen != nil {
+		snap.Generator = s.Gen.Name()
+	}
+	for _, e := range s.Enemies {
+		snap.Enemies = append(snap.Enemies, EnemySnapshot{e.Coords, e.Start, e.Path, e.Chasing, e.step.Remaining})
+	}
+	return snap
+}
+
+// Restore carries on a run from a Snapshot
+// Snapshots that don't make sense, e.g. from a corrupt save, are an error.
+func Restore(snap *Snapshot) (*Sim, error) {
+	if snap.Depth < 0 {
+		return nil, fmt.Errorf("depth %d out of range", snap.Depth)
+	}
+	if snap.Draws > maxDraws {
+		return nil, fmt.Errorf("%d random draws is too many", snap.Draws)
+	}
+	for _, st := range append([]Stats{snap.Stats}, snap.History...) {
+		if st.Level < 0 || st.Level >= len(LevelNames) {
+			return nil, fmt.Errorf("stats for level %d out of range", st.Level)
+		}
+	}
+	// A win is in the history as soon as the player reaches the exit
+	if snap.Done && !snap.Win {
+		return nil, errors.New("walked out of the maze without winning")
+	}
+	if snap.Win && (len(snap.History) == 0 || snap.History[len(snap.History)-1].Depth != snap.Depth) {
+		return nil, fmt.Errorf("won maze %d without it in the history", snap.Depth)
+	}
+	gen := GeneratorByName(snap.Generator)
+	if snap.Generator != "" && gen == nil {
+		return nil, fmt.Errorf("unknown maze generator %q", snap.Generator)
+	}
+	size := snap.Maze.Size
+	walls, err := decodeCells(size, snap.Maze.Walls)
+	if err != nil {
+		return nil, fmt.Errorf("maze walls: %w", err)
+	}
+	explored, err := decodeCells(size, snap.Maze.Explored)
+	if err != nil {
+		return nil, fmt.Errorf("explored maze: %w", err)
+	}
+
+	s := &Sim{
+		Seed:      snap.Seed,
+		Level:     min(snap.Depth, LevelExtreme),
+		Depth:     snap.Depth,
+		Endless:   snap.Endless,
+		Shifting:  snap.Shifting,
+		Gen:       gen,
+		HintsLeft: snap.HintsLeft,
+		Win:       snap.Win,
+		Done:      snap.Done,
+		Over:      snap.Over,
+		Clock:     timer.New(),
+		Ticks:     snap.Ticks,
+		Attempt:   snap.Attempt,
+		Stats:     snap.Stats,
+		History:   snap.History,
+		Source:    NewSource(snap.Seed, snap.Draws),
+		prev:      snap.Input,
+	}
+	s.Difficulty = Difficulties.At(s.difficultyDepth())
+	floors := max(1, snap.Maze.Floors)
+	if size.Y%floors != 0 {
+		return nil, fmt.Errorf("%d floors don't fit in a maze of size %v", floors, size)
+	}
+	s.Maze = &Maze{
+		Grid:      &Grid{Size: size, Walls: walls},
+		Floors:    floors,
+		FloorSize: image.Pt(size.X, size.Y/floors),
+		Exit:      snap.Maze.Exit,
+		Lit:       make([]bool, len(walls)),
+		Explored:  explored,
+	}
+	for i, o := range snap.Maze.Objects {
+		if o.Kind < ObjectKey || o.Kind > ObjectPortal {
+			return nil, fmt.Errorf("unknown object kind %d at %v", o.Kind, o.Coords)
+		}
+		if !s.Maze.Grid.In(o.Coords) || s.Maze.Grid.Wall(o.Coords) {
+			return nil, fmt.Errorf("object stuck in a wall at %v", o.Coords)
+		}
+		if o.Pair < 0 || o.Pair >= len(snap.Maze.Objects) || o.Pair == i {
+			return nil, fmt.Errorf("object at %v goes with missing object %d", o.Coords, o.Pair)
+		}
+		s.Maze.Objects = append(s.Maze.Objects, &o)
+	}
+	// Objects go in pairs that each point at the other, e.g. a key and its door
+	for i, o := range s.Maze.Objects {
+		pair := s.Maze.Objects[o.Pair]
+		if pair.Kind != o.Kind.pairs() || pair.Pair != i {
+			return nil, fmt.Errorf("object at %v doesn't go with the one at %v", o.Coords, pair.Coords)
+		}
+	}
+	for _, sh := range snap.Maze.Shifters {
+		if sh.Kind != ShiftRhythm && sh.Kind != ShiftTorch {
+			return nil, fmt.Errorf("unknown shifting wall kind %d at %v", sh.Kind, sh.Coords)
+		}
+		if !s.Maze.Grid.In(sh.Coords) || sh.Period <= 0 {
+			return nil, fmt.Errorf("bad shifting wall at %v", sh.Coords)
+		}
+		s.Maze.Shifters = append(s.Maze.Shifters, &sh)
+	}
+
+	p := snap.Player
+	if !s.Maze.Open(p.Coords) && !s.Win {
+		return nil, fmt.Errorf("player stuck in a wall at %v", p.Coords)
+	}
+	s.Player = NewPlayer(s.Difficulty, s.Clock)
+	s.Player.Coords = p.Coords
+	s.Player.TorchOn = p.TorchOn
+	s.Player.Battery.Charge = max(0, min(p.Charge, s.Player.Battery.Capacity))
+	s.Player.Step.Reset(p.Step)
+	s.Player.Moved = p.Moved
+
+	for _, es := range snap.Enemies {
+		// Minotaurs don't care about doors, only walls stop them
+		if s.Maze.Grid.Wall(es.Coords) || s.Maze.Grid.Wall(es.Start) {
+			return nil, fmt.Errorf("minotaur stuck in a wall at %v", es.Coords)
+		}
+		e := NewEnemy(es.Start, s.Difficulty.EnemySpeed, s.Source, s.Clock)
+		e.Coords = es.Coords
+		e.Path = es.Path
+		e.Chasing = es.Chasing
+		e.Area = s.Maze.FloorRect(s.Maze.FloorOf(es.Start))
+		e.step.Reset(es.Step)
+		s.Enemies = append(s.Enemies, e)
+	}
+
+	s.Trail = NewGhost(s)
+	for i, st := range snap.Trail {
+		if st.Tick > s.Attempt || i > 0 && st.Tick < snap.Trail[i-1].Tick {
+			return nil, fmt.Errorf("route goes back in time at tick %d", st.Tick)
+		}
+		if !s.Maze.Grid.In(st.Coords) && !st.Coords.Eq(s.Maze.Exit) {
+			return nil, fmt.Errorf("route goes outside the maze to %v", st.Coords)
+		}
+	}
+	s.Trail.Steps = snap.Trail
+	if s.Player.TorchOn {
+		s.Maze.Illuminate(s.Player.Coords, s.Player.TorchRadius())
+	}
+	return s, nil
+}
+
+// writes cells as rows of text, # where they're set and . where they're not
+func encodeCells(size image.Point, cells []bool) []string {
+	rows := make([]string, size.Y)
+	var b strings.Builder
+	for y := range rows {
+		b.Reset()
+		for x := 0; x < size.X; x++ {
+			if cells[y*size.X+x] {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		rows[y] = b.String()
+	}
+	return rows
+}
+
+// reads cells written by encodeCells, checking they're the right size
+func decodeCells(size image.Point, rows []string) ([]bool, error) {
+	if size.X <= 0 || size.Y <= 0 || len(rows) != size.Y {
+		return nil, fmt.Errorf("%d rows for a size of %v", len(rows), size)
+	}
+	cells := make([]bool, 0, size.X*size.Y)
+	for y, row := range rows {
+		if len(row) != size.X {
+			return nil, fmt.Errorf("row %d is %d long instead of %d", y, len(row), size.X)
+		}
+		for _, c := range row {
+			switch c {
+			case '#':
+				cells = append(cells, true)
+			case '.':
+				cells = append(cells, false)
+			default:
+				return nil, fmt.Errorf("row %d has a %q in it", y, c)
+			}
+		}
+	}
+	return cells, nil
+}
+
+// copies the maze's objects for a snapshot
+func (m *Maze) objects() []Object {
+	var objects []Object
+	for _, o := range m.Objects {
+		objects = append(objects, *o)
+	}
+	return objects
+}
+
+// copies the maze's shifting walls for a snapshot
+func (m *Maze) shifters() []Shifter {
+	var shifters []Shifter
+	for _, sh := range m.Shifters {
+		shifters = append(shifters, *sh)
+	}
+	return shifters
+}
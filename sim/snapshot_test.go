@@ -0,0 +1,102 @@
+package sim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// encodes a snapshot for comparing two sims
+func state(t *testing.T, s *Sim) string {
+	t.Helper()
+	b, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// Carrying on from a snapshot, saved and loaded again, goes exactly the same
+// way as the run it was taken from
+func TestRestoreCarriesOnTheSameRun(t *testing.T) {
+	runs := map[string]*Sim{
+		"levels":  New(5, LevelHard, nil, false),
+		"endless": NewEndless(5, nil, true),
+	}
+	for name, s := range runs {
+		t.Run(name, func(t *testing.T) {
+			recordRun(s, 2000)
+			saved, err := json.Marshal(s.Snapshot())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var snap Snapshot
+			if err := json.Unmarshal(saved, &snap); err != nil {
+				t.Fatal(err)
+			}
+			restored, err := Restore(&snap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := state(t, restored), string(saved); got != want {
+				t.Fatalf("restored to\n%s\nwant\n%s", got, want)
+			}
+
+			// Both carry on pressing the same buttons
+			recordRun(s, 3000)
+			recordRun(restored, 3000)
+			if got, want := state(t, restored), state(t, s); got != want {
+				t.Errorf("carried on to\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// A restored attempt at a maze still has the route taken so far, so it can be
+// kept as a ghost if it turns out to be the fastest
+func TestRestoreKeepsTheRouteSoFar(t *testing.T) {
+	s := New(5, LevelEasy, nil, false)
+	recordRun(s, 40)
+	if len(s.Trail.Steps) < 2 {
+		t.Fatal("didn't get anywhere to keep a route of")
+	}
+	restored, err := Restore(s.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.Trail, s.Trail) {
+		t.Errorf("restored route %+v, want %+v", restored.Trail, s.Trail)
+	}
+}
+
+func TestRestoreRejectsBrokenSnapshots(t *testing.T) {
+	breaks := map[string]func(*Snapshot){
+		"depth":     func(s *Snapshot) { s.Depth = -1 },
+		"generator": func(s *Snapshot) { s.Generator = "Nonsense" },
+		"walls":     func(s *Snapshot) { s.Maze.Walls = s.Maze.Walls[1:] },
+		"player":    func(s *Snapshot) { s.Player.Coords.X = 0 },
+		"floors":    func(s *Snapshot) { s.Maze.Floors = s.Maze.Size.Y + 1 },
+		"object":    func(s *Snapshot) { s.Maze.Objects[0].Kind = ObjectPortal + 1 },
+		"pair":      func(s *Snapshot) { s.Maze.Objects[0].Pair = 0 },
+		"pair kind": func(s *Snapshot) { s.Maze.Objects[0].Kind = (s.Maze.Objects[0].Kind + 2) % (ObjectPortal + 1) },
+		"shifter":   func(s *Snapshot) { s.Maze.Shifters[0].Kind = -1 },
+		"level":     func(s *Snapshot) { s.Stats.Level = len(LevelNames) },
+		"history":   func(s *Snapshot) { s.History = []Stats{{Level: -1}} },
+		"win":       func(s *Snapshot) { s.Win = true },
+		"won maze":  func(s *Snapshot) { s.Win, s.History = true, []Stats{{Depth: s.Depth + 1}} },
+		"done":      func(s *Snapshot) { s.Done = true },
+		"trail":     func(s *Snapshot) { s.Trail = []GhostStep{{s.Attempt + 1, s.Player.Coords}} },
+	}
+	for name, breakIt := range breaks {
+		t.Run(name, func(t *testing.T) {
+			snap := New(5, LevelMedium, nil, false).Snapshot()
+			if len(snap.Maze.Objects) == 0 || len(snap.Maze.Shifters) == 0 {
+				t.Fatal("nothing in the maze to break")
+			}
+			breakIt(snap)
+			if _, err := Restore(snap); err == nil {
+				t.Error("restored without an error")
+			}
+		})
+	}
+}
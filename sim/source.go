@@ -0,0 +1,38 @@
+package sim
+
+import "math/rand"
+
+// Source is a random source that counts how many numbers it has given out
+// A random source's state can't be saved, but starting a new one from the
+// same seed and drawing as many numbers gets it back to where it was.
+type Source struct {
+	Draws uint64 // How many numbers have been drawn since seeding
+	src   rand.Source64
+}
+
+// NewSource makes a Source from a seed that has already had some draws
+func NewSource(seed int64, draws uint64) *Source {
+	s := &Source{src: rand.NewSource(seed).(rand.Source64)}
+	for ; s.Draws < draws; s.Draws++ {
+		s.src.Uint64()
+	}
+	return s
+}
+
+// Int63 draws a random non-negative int64
+func (s *Source) Int63() int64 {
+	s.Draws++
+	return s.src.Int63()
+}
+
+// Uint64 draws a random uint64
+func (s *Source) Uint64() uint64 {
+	s.Draws++
+	return s.src.Uint64()
+}
+
+// Seed starts the Source over from a new seed
+func (s *Source) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.Draws = 0
+}
@@ -0,0 +1,89 @@
+package sim
+
+// HintPenalty is how many points each hint costs
+const HintPenalty int = 250
+
+// ParStepTicks is how many ticks par allows for each step through a maze
+const ParStepTicks int = 6
+
+// Stats are counts of how the player got through a maze
+type Stats struct {
+	Depth      int `json:"depth"`      // Which maze of the run these are for
+	Level      int `json:"level"`      // Difficulty level of the maze
+	Ticks      int `json:"ticks"`      // Time spent in the maze, restarts included
+	Steps      int `json:"steps"`      // Cells moved through
+	TorchTicks int `json:"torchTicks"` // Time spent with the torch lit
+	Bumps      int `json:"bumps"`      // Times the player walked into a wall
+	Hints      int `json:"hints"`      // Times the player asked for a hint
+	Par        int `json:"par"`        // Fewest steps it takes to get from the start to the exit
+}
+
+// Add sums up the counts of two sets of stats, e.g. for a whole run
+func (s Stats) Add(o Stats) Stats {
+	return Stats{
+		Depth:      max(s.Depth, o.Depth),
+		Level:      max(s.Level, o.Level),
+		Ticks:      s.Ticks + o.Ticks,
+		Steps:      s.Steps + o.Steps,
+		TorchTicks: s.TorchTicks + o.TorchTicks,
+		Bumps:      s.Bumps + o.Bumps,
+		Hints:      s.Hints + o.Hints,
+		Par:        s.Par + o.Par,
+	}
+}
+
+// TorchPercent is how much of the time the torch was lit
+func (s Stats) TorchPercent() int {
+	if s.Ticks == 0 {
+		return 0
+	}
+	return s.TorchTicks * 100 / s.Ticks
+}
+
+// ParTicks is how long it should take to get through the maze
+func (s Stats) ParTicks() int {
+	return s.Par * ParStepTicks
+}
+
+// Stars rates getting through the maze from 1 to 3 by how close to par it was
+// Three stars takes no more than a quarter more steps than the shortest way
+// and finishing within par time, two stars no more than twice the steps.
+func (s Stats) Stars() int {
+	switch {
+	case s.Steps*4 <= s.Par*5 && s.Ticks <= s.ParTicks():
+		return 3
+	case s.Steps <= s.Par*2:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Score is points for getting through the maze
+// Every step of par is worth up to 100 points, less for each extra step taken.
+// Beating par time gets a bonus, bumping into walls and hints lose points.
+func (s Stats) Score() int {
+	score := s.Par * 100 * s.Par / max(s.Steps, s.Par, 1)
+	score += max(0, s.ParTicks()*2-s.Ticks)
+	score -= s.Bumps * 10
+	score -= s.Hints * HintPenalty
+	return max(score, 0)
+}
+
+// Total sums up the stats of several mazes
+func Total(stats []Stats) Stats {
+	var total Stats
+	for _, s := range stats {
+		total = total.Add(s)
+	}
+	return total
+}
+
+// TotalScore adds up the scores of several mazes
+func TotalScore(stats []Stats) int {
+	score := 0
+	for _, s := range stats {
+		score += s.Score()
+	}
+	return score
+}
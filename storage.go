@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigPath returns where a file of the game's is kept in the user's config
+// directory, making the directories it needs along the way
+func ConfigPath(name ...string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(append([]string{dir, "dynamo"}, name...)...)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WriteFileAtomic writes a file so that it never ends up half-written
+// The data goes to a temporary file next to it first, which then replaces the
+// file in one go, so a crash leaves either the old or the new file behind.
+func WriteFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails harmlessly once renamed
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,98 @@
+// Package timer provides timers that count game ticks instead of wall time
+// Everything timed in the game is driven by the game loop through a Scheduler,
+// so it stops when the game is paused and happens the same way every run.
+package timer
+
+// Timer counts down a number of ticks and then fires
+type Timer struct {
+	Remaining int    // Ticks left until it fires
+	Period    int    // Ticks between firing for repeating timers, otherwise 0
+	fn        func() // Called when it fires, can be nil for cooldowns
+	stopped   bool
+	keep      bool // Stays scheduled after firing so it can be reset again
+}
+
+// Active reports whether the Timer is still counting down
+func (t *Timer) Active() bool {
+	return t.Remaining > 0 && !t.stopped
+}
+
+// Reset starts the Timer counting down again from a number of ticks
+func (t *Timer) Reset(ticks int) {
+	t.Remaining = ticks
+}
+
+// Stop cancels the Timer and removes it from its Scheduler
+func (t *Timer) Stop() {
+	t.stopped = true
+}
+
+// Scheduler runs timers as the game ticks
+type Scheduler struct {
+	Ticks  uint64 // How many ticks have been run in total
+	timers []*Timer
+}
+
+// New makes an empty Scheduler
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// After calls a function once after a number of ticks, at least 1
+func (s *Scheduler) After(ticks int, fn func()) *Timer {
+	ticks = max(ticks, 1)
+	return s.add(&Timer{Remaining: ticks, fn: fn})
+}
+
+// Every calls a function repeatedly, once every number of ticks, at least 1
+func (s *Scheduler) Every(ticks int, fn func()) *Timer {
+	ticks = max(ticks, 1)
+	return s.add(&Timer{Remaining: ticks, Period: ticks, fn: fn})
+}
+
+// Cooldown makes an idle Timer for waiting before something can happen again
+// It does nothing when it runs out, but stays scheduled so it can be Reset
+// every time it is used.
+func (s *Scheduler) Cooldown() *Timer {
+	return s.add(&Timer{keep: true})
+}
+
+// Update runs all the timers by one tick, firing the ones that are due
+func (s *Scheduler) Update() {
+	s.Ticks++
+	// Timers added by callbacks only start counting from the next tick
+	timers := s.timers
+	for _, t := range timers {
+		if t.stopped || t.Remaining <= 0 {
+			continue
+		}
+		t.Remaining--
+		if t.Remaining > 0 {
+			continue
+		}
+		if t.Period > 0 {
+			t.Remaining = t.Period
+		} else if !t.keep {
+			t.stopped = true
+		}
+		if t.fn != nil {
+			t.fn()
+		}
+	}
+
+	// Forget about timers that are finished
+	live := s.timers[:0]
+	for _, t := range s.timers {
+		if !t.stopped {
+			live = append(live, t)
+		}
+	}
+	clear(s.timers[len(live):])
+	s.timers = live
+}
+
+// adds a timer to the schedule
+func (s *Scheduler) add(t *Timer) *Timer {
+	s.timers = append(s.timers, t)
+	return t
+}
@@ -0,0 +1,133 @@
+package timer
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAfterFiresOnce(t *testing.T) {
+	s := New()
+	fired := 0
+	s.After(3, func() { fired++ })
+	for tick := 1; tick <= 10; tick++ {
+		s.Update()
+		want := 0
+		if tick >= 3 {
+			want = 1
+		}
+		if fired != want {
+			t.Fatalf("tick %d: fired %d times, want %d", tick, fired, want)
+		}
+	}
+	if s.Ticks != 10 {
+		t.Errorf("Ticks = %d, want 10", s.Ticks)
+	}
+}
+
+func TestAfterAtLeastOneTick(t *testing.T) {
+	s := New()
+	fired := false
+	s.After(0, func() { fired = true })
+	if fired {
+		t.Fatal("fired before any tick")
+	}
+	s.Update()
+	if !fired {
+		t.Fatal("didn't fire on the first tick")
+	}
+}
+
+func TestEveryRepeats(t *testing.T) {
+	s := New()
+	fired := 0
+	s.Every(4, func() { fired++ })
+	for range 20 {
+		s.Update()
+	}
+	if fired != 5 {
+		t.Errorf("fired %d times in 20 ticks, want 5", fired)
+	}
+}
+
+func TestStop(t *testing.T) {
+	s := New()
+	fired := 0
+	timer := s.Every(2, func() { fired++ })
+	s.Update()
+	s.Update()
+	timer.Stop()
+	for range 10 {
+		s.Update()
+	}
+	if fired != 1 {
+		t.Errorf("fired %d times, want 1", fired)
+	}
+	if timer.Active() {
+		t.Error("stopped timer is still active")
+	}
+	if len(s.timers) != 0 {
+		t.Errorf("%d timers still scheduled", len(s.timers))
+	}
+}
+
+func TestCooldownCanBeReset(t *testing.T) {
+	s := New()
+	c := s.Cooldown()
+	if c.Active() {
+		t.Fatal("new cooldown is active")
+	}
+	for round := range 3 {
+		c.Reset(2)
+		if !c.Active() {
+			t.Fatalf("round %d: not active after reset", round)
+		}
+		s.Update()
+		if !c.Active() {
+			t.Fatalf("round %d: ran out after 1 of 2 ticks", round)
+		}
+		s.Update()
+		if c.Active() {
+			t.Fatalf("round %d: still active after 2 of 2 ticks", round)
+		}
+	}
+}
+
+func TestTimersAddedByCallbacksStartNextTick(t *testing.T) {
+	s := New()
+	var order []uint64
+	s.After(1, func() {
+		s.After(1, func() { order = append(order, s.Ticks) })
+	})
+	for range 5 {
+		s.Update()
+	}
+	if len(order) != 1 || order[0] != 2 {
+		t.Errorf("inner timer fired at ticks %v, want [2]", order)
+	}
+}
+
+// Schedulers don't share anything, so separate ones can run side by side,
+// e.g. headless simulations in parallel; run with -race to check
+func TestSchedulersAreIndependent(t *testing.T) {
+	var wg sync.WaitGroup
+	counts := make([]int, 8)
+	for i := range counts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := New()
+			s.Every(1, func() { counts[i]++ })
+			cooldown := s.Cooldown()
+			for range 1000 {
+				cooldown.Reset(3)
+				s.Update()
+			}
+		}()
+	}
+	wg.Wait()
+	for i, n := range counts {
+		if n != 1000 {
+			t.Errorf("scheduler %d fired %d times, want 1000", i, n)
+		}
+	}
+}